@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/util"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type annotateOptions struct {
+	columns   bool
+	delimiter string
+	unknown   bool
+}
+
+func newAnnotateCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &annotateOptions{}
+	annotateCmd := &cobra.Command{
+		Use:   "annotate [FILE|-]...",
+		Short: "Annotate every IPv4 and IPv6 address found in log text with its provider",
+		Long: "Annotate scans free-form text such as access logs, syslog or firewall logs for IPv4 and IPv6 addresses " +
+			"and rewrites each line with the provider inlined, like 203.0.113.5[aws:us-east-1]. " +
+			"It reads stdin when no file is given.",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+
+			annotator := newAnnotator(checker, options, cmd.ErrOrStderr())
+			if len(args) == 0 {
+				args = []string{stdinInput}
+			}
+			for _, path := range args {
+				if err := annotateFile(cmd.InOrStdin(), cmd.OutOrStdout(), path, annotator); err != nil {
+					return err
+				}
+			}
+			if annotator.failed {
+				return errors.New("one or more IP checks failed")
+			}
+			return nil
+		},
+	}
+
+	annotateCmd.Flags().BoolVar(&options.columns, "columns", false, "Append annotations as extra columns instead of inlining them")
+	annotateCmd.Flags().StringVar(&options.delimiter, "delimiter", "\t", "Delimiter for the extra columns. Only applicable with --columns")
+	annotateCmd.Flags().BoolVar(&options.unknown, "unknown", false, "Also annotate addresses that belong to no provider")
+
+	return annotateCmd
+}
+
+// annotator labels the addresses of log lines. Logs repeat the same clients,
+// so the label of every address is cached.
+type annotator struct {
+	checker *ip.IPChecker
+	options *annotateOptions
+	stderr  io.Writer
+	labels  map[string]string
+	failed  bool
+}
+
+func newAnnotator(checker *ip.IPChecker, options *annotateOptions, stderr io.Writer) *annotator {
+	return &annotator{
+		checker: checker,
+		options: options,
+		stderr:  stderr,
+		labels:  make(map[string]string),
+	}
+}
+
+// label returns the annotation of an address, or "" when it is left as is.
+func (a *annotator) label(address string) string {
+	if label, exists := a.labels[address]; exists {
+		return label
+	}
+
+	result := a.checker.CheckInput(address)
+	label := ""
+	switch {
+	case result.Error != nil:
+		a.failed = true
+		printResultErrors(a.stderr, []common.Result{result})
+	case result.Provider == common.Reserved:
+		label = string(result.Provider) + ":" + result.Range.Service
+	case result.Provider != "":
+		label = string(result.Provider)
+		if result.Range.Region != "" {
+			label += ":" + result.Range.Region
+		}
+	case a.options.unknown:
+		label = "unknown"
+	}
+	a.labels[address] = label
+	return label
+}
+
+// annotate rewrites the line with every labelled address inlined, or appends
+// "address=label" columns.
+func (a *annotator) annotate(line string) string {
+	var builder strings.Builder
+	var columns []string
+	last := 0
+	for _, span := range util.FindAddresses(line) {
+		address := line[span.Start:span.End]
+		label := a.label(span.Addr.String())
+		if label == "" {
+			continue
+		}
+		if a.options.columns {
+			columns = append(columns, address+"="+label)
+			continue
+		}
+		builder.WriteString(line[last:span.End])
+		builder.WriteString("[" + label + "]")
+		last = span.End
+	}
+	builder.WriteString(line[last:])
+
+	for _, column := range columns {
+		builder.WriteString(a.options.delimiter + column)
+	}
+	return builder.String()
+}
+
+func annotateFile(stdin io.Reader, w io.Writer, path string, a *annotator) error {
+	if path == stdinInput {
+		return annotateLines(stdin, w, a)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening input file: %w", err)
+	}
+	defer file.Close()
+	return annotateLines(file, w, a)
+}
+
+func annotateLines(r io.Reader, w io.Writer, a *annotator) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintln(w, a.annotate(scanner.Text())); err != nil {
+			return fmt.Errorf("error writing annotated line: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading input: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"strings"
+	"testing"
+)
+
+type staticDataManager struct{}
+
+func (staticDataManager) EnsureDataFile() error {
+	return nil
+}
+
+func executeAnnotate(t *testing.T, input string, args ...string) (string, string, error) {
+	t.Helper()
+
+	aws := provider.NewBaseProvider("AWS", staticDataManager{}, func(bp *provider.BaseProvider) error {
+		if err := bp.AddRange(common.RangeInfo{Prefix: "203.0.113.0/24", Region: "us-east-1", Service: "EC2"}); err != nil {
+			return err
+		}
+		return bp.AddRange(common.RangeInfo{Prefix: "2001:db8::/32"})
+	})
+	checker := ip.NewIPChecker(map[common.CloudProvider]provider.CloudProvider{common.AWS: aws}, ip.DefaultProviderOrder)
+	cmd := NewRootCmd(&common.CloudIpFlag{}, checker)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetArgs(append([]string{"annotate"}, args...))
+
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestAnnotateInlinesProviders(t *testing.T) {
+	input := `203.0.113.5 - - [18/Oct/2026:10:15:30 +0000] "GET / HTTP/1.1" 200 612` + "\n" +
+		"sshd: Failed password from [2001:db8::7]:22 and 93.184.216.34\n"
+
+	stdout, _, err := executeAnnotate(t, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `203.0.113.5[aws:us-east-1] - - [18/Oct/2026:10:15:30 +0000] "GET / HTTP/1.1" 200 612` + "\n" +
+		"sshd: Failed password from [2001:db8::7[aws]]:22 and 93.184.216.34\n"
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
+
+func TestAnnotateAppendsColumns(t *testing.T) {
+	stdout, _, err := executeAnnotate(t, "DROP SRC=203.0.113.5 DST=93.184.216.34 NAT=10.0.0.5\n", "--columns", "--unknown", "--delimiter", ",")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "DROP SRC=203.0.113.5 DST=93.184.216.34 NAT=10.0.0.5,203.0.113.5=aws:us-east-1,93.184.216.34=unknown,10.0.0.5=reserved:private-use\n"
+	if stdout != expected {
+		t.Errorf("expected %q, got %q", expected, stdout)
+	}
+}
+
+func TestAnnotateRejectsInvalidStrategy(t *testing.T) {
+	stdout, _, err := executeAnnotate(t, "203.0.113.5\n", "--strategy", "random")
+	if err == nil || !strings.Contains(err.Error(), "random") {
+		t.Fatalf("expected invalid strategy error, got %v (output %q)", err, stdout)
+	}
+}
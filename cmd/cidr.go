@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/util"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type cidrOptions struct {
+	ipv4 bool
+	ipv6 bool
+}
+
+// cidrOperation combines the sets of the sources from left to right.
+type cidrOperation struct {
+	name    string
+	short   string
+	minArgs int
+	combine func(a, b util.PrefixSet) util.PrefixSet
+}
+
+var cidrOperations = []cidrOperation{
+	{"aggregate", "Collapse the sources into the minimal covering list of CIDRs", 1, util.PrefixSet.Union},
+	{"union", "Print the CIDRs covering addresses in any source", 2, util.PrefixSet.Union},
+	{"intersect", "Print the CIDRs covering addresses in every source", 2, util.PrefixSet.Intersect},
+	{"difference", "Print the CIDRs covering addresses in the first source but in no other", 2, util.PrefixSet.Difference},
+}
+
+func newCidrCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &cidrOptions{}
+	cidrCmd := &cobra.Command{
+		Use:   "cidr",
+		Short: "Aggregate provider ranges and CIDR lists and combine them as sets",
+		Long: "The cidr commands treat every SOURCE as a set of addresses and print the result as the minimal " +
+			"sorted list of CIDRs, one per line. A SOURCE is a PROVIDER[:SERVICE][@REGION] selection, a CIDR, " +
+			"an address range (FROM-TO), or a file listing CIDRs, ranges or addresses ('-' for stdin).",
+		Args: cobra.NoArgs,
+	}
+
+	for _, operation := range cidrOperations {
+		cidrCmd.AddCommand(&cobra.Command{
+			Use:   operation.name + " SOURCE...",
+			Short: operation.short,
+			Args:  cobra.MinimumNArgs(operation.minArgs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := configureChecker(flags, checker); err != nil {
+					return err
+				}
+
+				var result util.PrefixSet
+				for i, source := range args {
+					set, err := loadCIDRSource(cmd.InOrStdin(), checker, source)
+					if err != nil {
+						return err
+					}
+					if i == 0 {
+						result = set
+					} else {
+						result = operation.combine(result, set)
+					}
+				}
+				return printCIDRs(cmd.OutOrStdout(), result, options)
+			},
+		})
+	}
+
+	cidrCmd.PersistentFlags().BoolVar(&options.ipv4, "ipv4", false, "Only print IPv4 CIDRs")
+	cidrCmd.PersistentFlags().BoolVar(&options.ipv6, "ipv6", false, "Only print IPv6 CIDRs")
+	cidrCmd.MarkFlagsMutuallyExclusive("ipv4", "ipv6")
+
+	return cidrCmd
+}
+
+// loadCIDRSource returns the addresses of a source. Provider names take
+// precedence over files of the same name, which can be given as "./name".
+func loadCIDRSource(stdin io.Reader, checker *ip.IPChecker, source string) (util.PrefixSet, error) {
+	selector, _, _ := strings.Cut(source, "@")
+	name, _, _ := strings.Cut(selector, ":")
+	if _, exists := checker.Provider(common.CloudProvider(strings.ToLower(name))); exists {
+		ranges, err := selectRanges(checker, source)
+		if err != nil {
+			return util.PrefixSet{}, err
+		}
+		prefixes, err := rangePrefixes(ranges)
+		if err != nil {
+			return util.PrefixSet{}, err
+		}
+		return util.NewPrefixSet(prefixes...), nil
+	}
+
+	if prefixes, err := parseCIDR(source); err == nil {
+		return util.NewPrefixSet(prefixes...), nil
+	}
+
+	var prefixes []netip.Prefix
+	err := scanInputFile(stdin, source, func(line string) error {
+		parsed, err := parseCIDR(line)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", source, err)
+		}
+		prefixes = append(prefixes, parsed...)
+		return nil
+	})
+	if err != nil {
+		return util.PrefixSet{}, err
+	}
+	return util.NewPrefixSet(prefixes...), nil
+}
+
+// parseCIDR parses a CIDR, an address range or a single address.
+func parseCIDR(value string) ([]netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := util.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		return []netip.Prefix{prefix}, nil
+	}
+	if strings.Contains(value, "-") {
+		return util.ParseAddressRange(value)
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR, range or address %q", value)
+	}
+	addr = addr.Unmap()
+	return []netip.Prefix{netip.PrefixFrom(addr, addr.BitLen())}, nil
+}
+
+// printCIDRs prints the prefixes of the set, one per line.
+func printCIDRs(w io.Writer, set util.PrefixSet, options *cidrOptions) error {
+	for _, prefix := range set.Prefixes() {
+		if options.ipv4 && !prefix.Addr().Is4() || options.ipv6 && prefix.Addr().Is4() {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, prefix); err != nil {
+			return fmt.Errorf("error writing CIDRs: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCidrOperations(t *testing.T) {
+	ours := filepath.Join(t.TempDir(), "ours.txt")
+	content := "# office and data center\n54.230.16.0/20\n3.5.0.0-3.5.7.255\n\n2600:9000::1\n"
+	if err := os.WriteFile(ours, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write CIDR file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "aggregate",
+			args:     []string{"cidr", "aggregate", "aws", "54.231.0.0/16"},
+			expected: "3.5.0.0/19\n54.230.0.0/15\n2600:9000::/28\n",
+		},
+		{
+			name:     "union",
+			args:     []string{"cidr", "union", "aws:S3", "azure", "--ipv4"},
+			expected: "3.5.0.0/19\n20.36.0.0/19\n",
+		},
+		{
+			name:     "intersect",
+			args:     []string{"cidr", "intersect", "aws", ours},
+			expected: "3.5.0.0/21\n54.230.16.0/20\n2600:9000::1/128\n",
+		},
+		{
+			name:     "difference",
+			args:     []string{"cidr", "difference", "aws@GLOBAL", ours, "--ipv4"},
+			expected: "54.230.0.0/20\n54.230.32.0/19\n54.230.64.0/18\n54.230.128.0/17\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := executeWithRangeProviders(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestCidrRejectsInvalidSources(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.txt")
+	if err := os.WriteFile(invalid, []byte("10.0.0.0/8\nnot-a-cidr\n"), 0o600); err != nil {
+		t.Fatalf("failed to write CIDR file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		message string
+	}{
+		{"missing operand", []string{"cidr", "difference", "aws"}, "requires at least 2 arg(s)"},
+		{"selection without ranges", []string{"cidr", "aggregate", "aws:EC2"}, "no ranges found for aws:EC2"},
+		{"invalid line", []string{"cidr", "aggregate", invalid}, "not-a-cidr"},
+		{"missing file", []string{"cidr", "aggregate", "missing.txt"}, "error opening input file"},
+		{"both families", []string{"cidr", "aggregate", "aws", "--ipv4", "--ipv6"}, "none of the others can be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeWithRangeProviders(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type diffOptions struct {
+	list   bool
+	format string
+}
+
+// providerDiff is the change between two archived versions of one provider.
+type providerDiff struct {
+	provider common.CloudProvider
+	from     common.ArchivedVersion
+	to       common.ArchivedVersion
+	changes  []ip.RangeChange
+}
+
+type jsonRangeChange struct {
+	Provider string `json:"provider"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Change   string `json:"change"`
+	Prefix   string `json:"prefix"`
+	Region   string `json:"region"`
+	Service  string `json:"service"`
+}
+
+type jsonArchivedVersion struct {
+	Provider  string `json:"provider"`
+	Signature string `json:"signature"`
+	FetchedAt string `json:"fetchedAt"`
+	Count     int    `json:"count"`
+}
+
+func newDiffCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &diffOptions{}
+	diffCmd := &cobra.Command{
+		Use:   "diff [PROVIDER [FROM [TO]]]",
+		Short: "Show the ranges added and removed between archived provider data versions",
+		Long: "Every provider data version cloudip downloads is archived under its signature. Diff lists the ranges " +
+			"removed (-) and added (+) per provider, region and service between the last two versions, or between " +
+			"the FROM and TO signatures. TO defaults to the latest version. Use --list to show the archived versions.",
+		Args: cobra.MaximumNArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+			if options.format != "text" && options.format != "json" {
+				return fmt.Errorf("invalid output format: %s. Supported formats are: text, json", options.format)
+			}
+
+			providers := checker.Providers()
+			if len(args) > 0 {
+				providers = []common.CloudProvider{common.CloudProvider(strings.ToLower(args[0]))}
+			}
+
+			var versions []jsonArchivedVersion
+			var diffs []providerDiff
+			for _, providerType := range providers {
+				archive, err := providerArchive(checker, providerType, len(args) > 0)
+				if err != nil {
+					return err
+				}
+				if archive == nil {
+					continue
+				}
+				archived, err := archive.Versions()
+				if err != nil {
+					return err
+				}
+
+				if options.list {
+					for _, version := range archived {
+						versions = append(versions, jsonArchivedVersion{
+							Provider:  string(providerType),
+							Signature: version.Signature,
+							FetchedAt: version.FetchedAt.Format(time.RFC3339),
+							Count:     version.Count,
+						})
+					}
+					continue
+				}
+
+				diff, err := diffVersions(archive, archived, args[min(len(args), 1):])
+				if err != nil {
+					return err
+				}
+				if diff == nil {
+					if len(args) > 0 {
+						return fmt.Errorf("%s has %d archived version(s); diff needs two", providerType, len(archived))
+					}
+					continue
+				}
+				diffs = append(diffs, *diff)
+			}
+
+			if options.list {
+				return printArchivedVersions(cmd.OutOrStdout(), versions, options)
+			}
+			return printDiffs(cmd.OutOrStdout(), diffs, options)
+		},
+	}
+
+	diffCmd.Flags().BoolVar(&options.list, "list", false, "List the archived versions instead of comparing them")
+	diffCmd.Flags().StringVarP(&options.format, "format", "f", "text", "Output format (text, json)")
+
+	return diffCmd
+}
+
+// providerArchive returns the archive of the provider. Providers without an
+// archive are an error only when they were asked for by name.
+func providerArchive(checker *ip.IPChecker, providerType common.CloudProvider, named bool) (*common.DataArchive, error) {
+	p, exists := checker.Provider(providerType)
+	if !exists {
+		var supported []string
+		for _, registered := range checker.Providers() {
+			supported = append(supported, string(registered))
+		}
+		return nil, fmt.Errorf("unknown provider: %s. Supported providers are: %s", providerType, strings.Join(supported, ", "))
+	}
+
+	archiver, ok := p.(provider.Archiver)
+	if !ok {
+		if named {
+			return nil, fmt.Errorf("provider %s does not archive its data", providerType)
+		}
+		return nil, nil
+	}
+	return archiver.Archive()
+}
+
+// diffVersions compares the versions named by signatures, [FROM [TO]], or the
+// last two versions. It returns nil when the archive has too few versions.
+func diffVersions(archive *common.DataArchive, versions []common.ArchivedVersion, signatures []string) (*providerDiff, error) {
+	find := func(signature string) (int, error) {
+		for i, version := range versions {
+			if version.Signature == signature {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown %s version: %s", archive.Provider, signature)
+	}
+
+	toIndex := len(versions) - 1
+	if len(signatures) > 1 {
+		index, err := find(signatures[1])
+		if err != nil {
+			return nil, err
+		}
+		toIndex = index
+	}
+	fromIndex := toIndex - 1
+	if len(signatures) > 0 {
+		index, err := find(signatures[0])
+		if err != nil {
+			return nil, err
+		}
+		fromIndex = index
+	}
+	if fromIndex < 0 || toIndex < 0 {
+		return nil, nil
+	}
+
+	from, err := archive.Load(versions[fromIndex])
+	if err != nil {
+		return nil, err
+	}
+	to, err := archive.Load(versions[toIndex])
+	if err != nil {
+		return nil, err
+	}
+	return &providerDiff{
+		provider: archive.Provider,
+		from:     from.ArchivedVersion,
+		to:       to.ArchivedVersion,
+		changes:  ip.DiffRanges(from.Ranges, to.Ranges),
+	}, nil
+}
+
+func printDiffs(w io.Writer, diffs []providerDiff, options *diffOptions) error {
+	if options.format == "json" {
+		changes := make([]jsonRangeChange, 0)
+		for _, diff := range diffs {
+			for _, change := range diff.changes {
+				kind := "removed"
+				if change.Added {
+					kind = "added"
+				}
+				changes = append(changes, jsonRangeChange{
+					Provider: string(diff.provider),
+					From:     diff.from.Signature,
+					To:       diff.to.Signature,
+					Change:   kind,
+					Prefix:   change.Range.Prefix,
+					Region:   change.Range.Region,
+					Service:  change.Range.Service,
+				})
+			}
+		}
+		return writeJSONLine(w, changes, "diff")
+	}
+
+	for _, diff := range diffs {
+		if _, err := fmt.Fprintf(w, "# %s %s (%s) -> %s (%s)\n", diff.provider,
+			diff.from.Signature, diff.from.FetchedAt.Format(time.RFC3339),
+			diff.to.Signature, diff.to.FetchedAt.Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("error writing diff: %w", err)
+		}
+		for _, change := range diff.changes {
+			sign := "-"
+			if change.Added {
+				sign = "+"
+			}
+			row := []string{sign, string(diff.provider), change.Range.Prefix, getFieldString(change.Range.Region), getFieldString(change.Range.Service)}
+			if _, err := fmt.Fprintln(w, strings.Join(row, " ")); err != nil {
+				return fmt.Errorf("error writing diff: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func printArchivedVersions(w io.Writer, versions []jsonArchivedVersion, options *diffOptions) error {
+	if options.format == "json" {
+		if versions == nil {
+			versions = make([]jsonArchivedVersion, 0)
+		}
+		return writeJSONLine(w, versions, "archived versions")
+	}
+
+	for _, version := range versions {
+		if _, err := fmt.Fprintf(w, "%s %s %s %d\n", version.Provider, version.FetchedAt, version.Signature, version.Count); err != nil {
+			return fmt.Errorf("error writing archived versions: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSONLine writes the value as a single line of JSON.
+func writeJSONLine(w io.Writer, value any, name string) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error converting %s to JSON: %w", name, err)
+	}
+	if _, err := fmt.Fprintln(w, string(bytes)); err != nil {
+		return fmt.Errorf("error writing JSON %s: %w", name, err)
+	}
+	return nil
+}
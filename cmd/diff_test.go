@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// archiveProvider serves a fixed archive of data versions.
+type archiveProvider struct {
+	*provider.BaseProvider
+	archive *common.DataArchive
+}
+
+func (p *archiveProvider) Archive() (*common.DataArchive, error) {
+	return p.archive, nil
+}
+
+func (p *archiveProvider) Archived() *common.DataArchive {
+	return p.archive
+}
+
+// executeWithArchive runs the root command with an AWS provider that has
+// archived three versions and an Azure provider without an archive.
+func executeWithArchive(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	archive := &common.DataArchive{Provider: common.AWS, Dir: t.TempDir()}
+	fetchedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	versions := [][]common.RangeInfo{
+		{{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}},
+		{{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}, {Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"}},
+		{{Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"}, {Prefix: "2600:9000::/28", Region: "GLOBAL", Service: "CLOUDFRONT"}},
+	}
+	for i, ranges := range versions {
+		signature := []string{"v1", "v2", "v3"}[i]
+		if err := archive.Save(signature, fetchedAt.AddDate(0, 0, i), ranges); err != nil {
+			t.Fatalf("failed to archive %s: %v", signature, err)
+		}
+	}
+
+	aws := &archiveProvider{
+		BaseProvider: provider.NewBaseProvider("AWS", staticDataManager{}, func(*provider.BaseProvider) error { return nil }),
+		archive:      archive,
+	}
+	azure := provider.NewBaseProvider("Azure", staticDataManager{}, func(*provider.BaseProvider) error { return nil })
+	checker := ip.NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: aws, common.Azure: azure},
+		ip.DefaultProviderOrder,
+	)
+	cmd := NewRootCmd(&common.CloudIpFlag{}, checker)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestDiffComparesArchivedVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "last two versions",
+			args: []string{"diff"},
+			expected: "# aws v2 (2026-03-02T00:00:00Z) -> v3 (2026-03-03T00:00:00Z)\n" +
+				"- aws 54.230.0.0/16 GLOBAL CLOUDFRONT\n" +
+				"+ aws 2600:9000::/28 GLOBAL CLOUDFRONT\n",
+		},
+		{
+			name: "from and to",
+			args: []string{"diff", "AWS", "v1", "v2"},
+			expected: "# aws v1 (2026-03-01T00:00:00Z) -> v2 (2026-03-02T00:00:00Z)\n" +
+				"+ aws 3.5.0.0/19 us-east-1 S3\n",
+		},
+		{
+			name: "from to latest",
+			args: []string{"diff", "aws", "v1"},
+			expected: "# aws v1 (2026-03-01T00:00:00Z) -> v3 (2026-03-03T00:00:00Z)\n" +
+				"+ aws 3.5.0.0/19 us-east-1 S3\n" +
+				"- aws 54.230.0.0/16 GLOBAL CLOUDFRONT\n" +
+				"+ aws 2600:9000::/28 GLOBAL CLOUDFRONT\n",
+		},
+		{
+			name: "list",
+			args: []string{"diff", "--list"},
+			expected: "aws 2026-03-01T00:00:00Z v1 1\n" +
+				"aws 2026-03-02T00:00:00Z v2 2\n" +
+				"aws 2026-03-03T00:00:00Z v3 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := executeWithArchive(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestDiffJSONFormat(t *testing.T) {
+	stdout, err := executeWithArchive(t, "diff", "aws", "v2", "v3", "--format", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var changes []jsonRangeChange
+	if err := json.Unmarshal([]byte(stdout), &changes); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v, stdout: %q", err, stdout)
+	}
+	if len(changes) != 2 || changes[0].Change != "removed" || changes[1].Change != "added" || changes[1].From != "v2" || changes[1].To != "v3" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestDiffRejectsInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		message string
+	}{
+		{"unknown provider", []string{"diff", "oracle"}, "unknown provider: oracle"},
+		{"provider without archive", []string{"diff", "azure"}, "provider azure does not archive its data"},
+		{"unknown version", []string{"diff", "aws", "v0"}, "unknown aws version: v0"},
+		{"invalid format", []string{"diff", "--format", "table"}, "invalid output format: table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeWithArchive(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
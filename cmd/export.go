@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/export"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type exportOptions struct {
+	ipv4       bool
+	ipv6       bool
+	target     string
+	maxEntries int
+}
+
+func newExportCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &exportOptions{}
+	exportCmd := &cobra.Command{
+		Use:   "export FORMAT PROVIDER[:SERVICE][@REGION]...",
+		Short: "Export provider ranges as firewall rule sets, server configuration, manifests and Terraform inputs",
+		Long: fmt.Sprintf("Export turns the cached provider ranges into ready-to-load artifacts. "+
+			"Every PROVIDER[:SERVICE][@REGION] selection becomes its own set, with IPv4 and IPv6 kept separate. "+
+			"Supported formats are: %s.", strings.Join(export.Formats(), ", ")),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+
+			selections := args[1:]
+			selected := make([][]export.RangeSet, 0, len(selections))
+			for _, selection := range selections {
+				selectionSets, err := loadRangeSets(checker, selection)
+				if err != nil {
+					return err
+				}
+				selected = append(selected, selectionSets)
+			}
+			sets, err := uniqueRangeSets(selections, selected)
+			if err != nil {
+				return err
+			}
+
+			if options.maxEntries < 0 {
+				return fmt.Errorf("invalid max entries: %d", options.maxEntries)
+			}
+			family := export.AllFamilies
+			if options.ipv4 {
+				family = export.IPv4
+			} else if options.ipv6 {
+				family = export.IPv6
+			}
+			return export.Render(cmd.OutOrStdout(), args[0], sets, export.Options{
+				Family:     family,
+				Target:     options.target,
+				MaxEntries: options.maxEntries,
+			})
+		},
+	}
+
+	exportCmd.Flags().BoolVar(&options.ipv4, "ipv4", false, "Only export IPv4 sets")
+	exportCmd.Flags().BoolVar(&options.ipv6, "ipv6", false, "Only export IPv6 sets")
+	exportCmd.MarkFlagsMutuallyExclusive("ipv4", "ipv6")
+	exportCmd.Flags().StringVar(&options.target, "target", "ACCEPT", "Jump target of the generated rules. Only applicable for 'iptables' format")
+	exportCmd.Flags().IntVar(&options.maxEntries, "max-entries", 0, "Widen prefixes until every set has at most this many entries. Only applicable for 'terraform', 'tfvars-json' and 'aws-prefix-list' formats. No limit when 0")
+
+	return exportCmd
+}
+
+// loadRangeSets returns the sets of a "provider[:service][@region]"
+// selection, built from the same provider data the lookups use.
+func loadRangeSets(checker *ip.IPChecker, selection string) ([]export.RangeSet, error) {
+	ranges, err := selectRanges(checker, selection)
+	if err != nil {
+		return nil, err
+	}
+	prefixes, err := rangePrefixes(ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, region, _ := strings.Cut(selection, "@")
+	name, service, _ := strings.Cut(selector, ":")
+	template := export.RangeSet{Provider: common.CloudProvider(strings.ToLower(name))}
+	if service != "" {
+		template.Service = ranges[0].Service
+	}
+	if region != "" {
+		template.Region = ranges[0].Region
+	}
+	p, _ := checker.Provider(template.Provider)
+	if reporter, ok := p.(provider.SignatureReporter); ok {
+		template.Signature = reporter.Signature()
+	}
+	return export.NewRangeSets(template, prefixes), nil
+}
+
+// uniqueRangeSets returns the sets of the selections, dropping selections
+// that repeat an earlier one, such as "aws:ec2" after "aws:EC2". Formats tell
+// sets apart by name, so different selections whose sets would get the same
+// name are an error.
+func uniqueRangeSets(selections []string, selected [][]export.RangeSet) ([]export.RangeSet, error) {
+	var sets []export.RangeSet
+	first := make(map[string]int)
+	for i, selectionSets := range selected {
+		set := selectionSets[0]
+		name := set.SelectionName("-", 0)
+		if j, exists := first[name]; exists {
+			previous := selected[j][0]
+			if previous.Provider == set.Provider && previous.Service == set.Service && previous.Region == set.Region {
+				continue
+			}
+			return nil, fmt.Errorf("selections %s and %s would both export sets named %s", selections[j], selections[i], name)
+		}
+		first[name] = i
+		sets = append(sets, selectionSets...)
+	}
+	return sets, nil
+}
+
+// selectRanges returns the ranges of a "provider[:service][@region]"
+// selection. A selection without ranges is an error so an export never
+// silently produces an empty allowlist.
+func selectRanges(checker *ip.IPChecker, selection string) ([]common.RangeInfo, error) {
+	selector, region, _ := strings.Cut(selection, "@")
+	name, service, _ := strings.Cut(selector, ":")
+	ranges, err := loadProviderRanges(checker, name)
+	if err != nil {
+		return nil, err
+	}
+	ranges = filterRanges(ranges, &rangesOptions{service: service, region: region})
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ranges found for %s", selection)
+	}
+	return ranges, nil
+}
+
+// rangePrefixes parses the prefixes of the ranges.
+func rangePrefixes(ranges []common.RangeInfo) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(ranges))
+	for _, info := range ranges {
+		prefix, err := util.ParsePrefix(info.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
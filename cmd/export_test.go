@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/export"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestExportSelectsProvidersAndServices(t *testing.T) {
+	stdout, err := executeWithRangeProviders(t, "export", "ipset", "aws:cloudfront", "azure", "--ipv4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: aws CLOUDFRONT\n" +
+		"# Source: azure\n" +
+		"create cloudip-aws-cloudfront-v4 hash:net family inet maxelem 65536 -exist\n" +
+		"flush cloudip-aws-cloudfront-v4\n" +
+		"add cloudip-aws-cloudfront-v4 54.230.0.0/16\n" +
+		"create cloudip-azure-v4 hash:net family inet maxelem 65536 -exist\n" +
+		"flush cloudip-azure-v4\n" +
+		"add cloudip-azure-v4 20.36.0.0/19\n"
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
+
+func TestExportRejectsInvalidSelections(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		message string
+	}{
+		{"unknown format", []string{"export", "pf", "aws"}, "invalid export format"},
+		{"unknown provider", []string{"export", "nftables", "oracle"}, "unknown provider"},
+		{"service without ranges", []string{"export", "nftables", "aws:EC2"}, "no ranges found for aws:EC2"},
+		{"iptables without family", []string{"export", "iptables", "aws"}, "single IP family"},
+		{"negative max entries", []string{"export", "terraform", "aws", "--max-entries", "-1"}, "invalid max entries: -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeWithRangeProviders(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestExportSelectsRegions(t *testing.T) {
+	stdout, err := executeWithRangeProviders(t, "export", "cilium-cidrgroup", "aws:s3@US-EAST-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: aws S3 us-east-1\n" +
+		"apiVersion: cilium.io/v2alpha1\n" +
+		"kind: CiliumCIDRGroup\n" +
+		"metadata:\n" +
+		"  name: cloudip-aws-s3-us-east-1\n" +
+		"  labels:\n" +
+		"    app.kubernetes.io/managed-by: cloudip\n" +
+		"    cloudip/provider: aws\n" +
+		"    cloudip/service: s3\n" +
+		"    cloudip/region: us-east-1\n" +
+		"spec:\n" +
+		"  externalCIDRs:\n" +
+		"  - \"3.5.0.0/19\"\n"
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+
+	if _, err := executeWithRangeProviders(t, "export", "k8s-networkpolicy", "aws@eu-west-1"); err == nil || !strings.Contains(err.Error(), "no ranges found for aws@eu-west-1") {
+		t.Errorf("expected error for region without ranges, got %v", err)
+	}
+}
+
+func TestExportDropsRepeatedSelections(t *testing.T) {
+	stdout, err := executeWithRangeProviders(t, "export", "ipset", "aws:cloudfront", "azure", "AWS:CloudFront", "azure", "--ipv4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Count(stdout, "create cloudip-aws-cloudfront-v4 ") != 1 || strings.Count(stdout, "create cloudip-azure-v4 ") != 1 {
+		t.Errorf("expected every set to be created once, got:\n%s", stdout)
+	}
+}
+
+func TestUniqueRangeSetsRejectsNameCollisions(t *testing.T) {
+	prefixes := []netip.Prefix{netip.MustParsePrefix("3.5.0.0/19")}
+	selected := [][]export.RangeSet{
+		export.NewRangeSets(export.RangeSet{Provider: common.AWS, Region: "us-east-1"}, prefixes),
+		export.NewRangeSets(export.RangeSet{Provider: common.AWS, Region: "us_east_1"}, prefixes),
+	}
+
+	_, err := uniqueRangeSets([]string{"aws@us-east-1", "aws@us_east_1"}, selected)
+	if err == nil || !strings.Contains(err.Error(), "selections aws@us-east-1 and aws@us_east_1 would both export sets named cloudip-aws-us-east-1") {
+		t.Errorf("expected name collision error, got %v", err)
+	}
+}
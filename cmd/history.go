@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/ip"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type historyOptions struct {
+	format string
+}
+
+// providerEvent is an address event in the archive of one provider.
+type providerEvent struct {
+	provider common.CloudProvider
+	event    ip.AddressEvent
+}
+
+type jsonAddressEvent struct {
+	Provider  string `json:"provider"`
+	Signature string `json:"signature"`
+	FetchedAt string `json:"fetchedAt"`
+	Event     string `json:"event"`
+	Prefix    string `json:"prefix"`
+	Region    string `json:"region"`
+	Service   string `json:"service"`
+}
+
+func newHistoryCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &historyOptions{}
+	historyCmd := &cobra.Command{
+		Use:   "history IP [PROVIDER]...",
+		Short: "Show when an address entered or left the archived ranges of each provider",
+		Long: "History looks the address up in every archived provider data version, oldest first, and prints the " +
+			"versions in which it entered (entered) or left (left) a provider's ranges or moved to another range, " +
+			"region or service (changed). An address already published in the oldest version is reported as present.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+			if options.format != "text" && options.format != "json" {
+				return fmt.Errorf("invalid output format: %s. Supported formats are: text, json", options.format)
+			}
+			addr, err := netip.ParseAddr(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid IP address: %s", args[0])
+			}
+
+			providers := checker.Providers()
+			if len(args) > 1 {
+				providers = nil
+				for _, name := range args[1:] {
+					providers = append(providers, common.CloudProvider(strings.ToLower(name)))
+				}
+			}
+
+			var events []providerEvent
+			for _, providerType := range providers {
+				archive, err := providerArchive(checker, providerType, len(args) > 1)
+				if err != nil {
+					return err
+				}
+				if archive == nil {
+					continue
+				}
+				history, err := ip.AddressHistory(archive, addr.Unmap())
+				if err != nil {
+					return err
+				}
+				for _, event := range history {
+					events = append(events, providerEvent{provider: providerType, event: event})
+				}
+			}
+			return printHistory(cmd.OutOrStdout(), events, options)
+		},
+	}
+
+	historyCmd.Flags().StringVarP(&options.format, "format", "f", "text", "Output format (text, json)")
+
+	return historyCmd
+}
+
+// checkerAsOf returns a checker answering from the archived data selected by
+// --as-of, or the checker itself without the flag.
+func checkerAsOf(flags *common.CloudIpFlag, checker *ip.IPChecker) (*ip.IPChecker, error) {
+	if flags.AsOf == "" {
+		return checker, nil
+	}
+	at, err := common.ParseAsOf(flags.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	return checker.AsOf(at), nil
+}
+
+func printHistory(w io.Writer, events []providerEvent, options *historyOptions) error {
+	if options.format == "json" {
+		jsonEvents := make([]jsonAddressEvent, 0, len(events))
+		for _, entry := range events {
+			jsonEvents = append(jsonEvents, jsonAddressEvent{
+				Provider:  string(entry.provider),
+				Signature: entry.event.Version.Signature,
+				FetchedAt: entry.event.Version.FetchedAt.Format(time.RFC3339),
+				Event:     string(entry.event.Kind),
+				Prefix:    entry.event.Range.Prefix,
+				Region:    entry.event.Range.Region,
+				Service:   entry.event.Range.Service,
+			})
+		}
+		return writeJSONLine(w, jsonEvents, "history")
+	}
+
+	for _, entry := range events {
+		event := entry.event
+		row := []string{
+			string(entry.provider), event.Version.FetchedAt.Format(time.RFC3339), event.Version.Signature, string(event.Kind),
+			event.Range.Prefix, getFieldString(event.Range.Region), getFieldString(event.Range.Service),
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, " ")); err != nil {
+			return fmt.Errorf("error writing history: %w", err)
+		}
+	}
+	return nil
+}
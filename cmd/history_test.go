@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHistoryShowsWhenAddressEnteredAndLeft(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "present and left",
+			args: []string{"history", "54.230.1.1"},
+			expected: "aws 2026-03-01T00:00:00Z v1 present 54.230.0.0/16 GLOBAL CLOUDFRONT\n" +
+				"aws 2026-03-03T00:00:00Z v3 left 54.230.0.0/16 GLOBAL CLOUDFRONT\n",
+		},
+		{
+			name:     "entered",
+			args:     []string{"history", "3.5.0.1", "AWS"},
+			expected: "aws 2026-03-02T00:00:00Z v2 entered 3.5.0.0/19 us-east-1 S3\n",
+		},
+		{
+			name:     "IPv6",
+			args:     []string{"history", "2600:9000::1"},
+			expected: "aws 2026-03-03T00:00:00Z v3 entered 2600:9000::/28 GLOBAL CLOUDFRONT\n",
+		},
+		{
+			name:     "never published",
+			args:     []string{"history", "8.8.8.8"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := executeWithArchive(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestHistoryJSONFormat(t *testing.T) {
+	stdout, err := executeWithArchive(t, "history", "54.230.1.1", "--format", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []jsonAddressEvent
+	if err := json.Unmarshal([]byte(stdout), &events); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v, stdout: %q", err, stdout)
+	}
+	if len(events) != 2 || events[0].Event != "present" || events[1].Event != "left" || events[1].Signature != "v3" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestHistoryRejectsInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		message string
+	}{
+		{"invalid address", []string{"history", "3.5.0.0/19"}, "invalid IP address: 3.5.0.0/19"},
+		{"provider without archive", []string{"history", "3.5.0.1", "azure"}, "provider azure does not archive its data"},
+		{"invalid format", []string{"history", "3.5.0.1", "--format", "table"}, "invalid output format: table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeWithArchive(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestAsOfChecksArchivedVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"removed since", []string{"54.230.1.1", "--as-of", "2026-03-02"}, "54.230.1.1 aws 54.230.0.0/16 GLOBAL CLOUDFRONT\n"},
+		{"time of day", []string{"3.5.0.1", "--as-of", "2026-03-02T12:00:00Z"}, "3.5.0.1 aws 3.5.0.0/19 us-east-1 S3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := executeWithArchive(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, stdout)
+			}
+		})
+	}
+
+	stdout, err := executeWithArchive(t, "3.5.0.1", "--as-of", "2026-03-01")
+	if err != nil || stdout != "3.5.0.1 unknown - - -\n" {
+		t.Errorf("expected providers without an archive to be skipped, got %q, %v", stdout, err)
+	}
+	if _, err := executeWithArchive(t, "54.230.1.1", "--as-of", "yesterday"); err == nil || !strings.Contains(err.Error(), "invalid date: yesterday") {
+		t.Errorf("expected invalid date error, got %v", err)
+	}
+}
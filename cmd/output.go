@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
@@ -21,15 +23,235 @@ func getProviderString(r common.Result) string {
 	return string(r.Provider)
 }
 
+func getFieldString(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 var headers = map[string]string{
+	"Host":     "Host",
 	"IP":       "IP",
 	"Provider": "Provider",
+	"Prefix":   "Prefix",
+	"Region":   "Region",
+	"Service":  "Service",
+	"Coverage": "Coverage",
+	"Embedded": "Embedded",
 }
 
+var headerOrder = []string{"IP", "Provider", "Prefix", "Region", "Service"}
+
 type jsonResult struct {
-	IP       string `json:"ip"`
-	Provider string `json:"provider"`
-	Error    string `json:"error"`
+	IP         string            `json:"ip"`
+	Provider   string            `json:"provider"`
+	Prefix     string            `json:"prefix"`
+	Region     string            `json:"region"`
+	Service    string            `json:"service"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Matches    []jsonMatch       `json:"matches,omitempty"`
+	Strategy   string            `json:"strategy,omitempty"`
+	Coverage   string            `json:"coverage,omitempty"`
+	Embedded   *jsonEmbedded     `json:"embedded,omitempty"`
+	Error      string            `json:"error"`
+}
+
+// jsonEmbedded is the result of the IPv4 address embedded in an IPv6
+// transition address.
+type jsonEmbedded struct {
+	Embedding string `json:"embedding"`
+	jsonResult
+}
+
+// jsonHostResult groups the results of the addresses a hostname resolved to.
+type jsonHostResult struct {
+	Host      string       `json:"host"`
+	Addresses []jsonResult `json:"addresses"`
+	Error     string       `json:"error"`
+}
+
+type jsonMatch struct {
+	Provider   string            `json:"provider"`
+	Prefix     string            `json:"prefix"`
+	Region     string            `json:"region"`
+	Service    string            `json:"service"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Coverage   string            `json:"coverage,omitempty"`
+}
+
+// outputColumns are the optional columns of the text and table output.
+type outputColumns struct {
+	host     bool
+	coverage bool
+	embedded bool
+}
+
+func getOutputColumns(results []common.Result) outputColumns {
+	return outputColumns{
+		host:     hasHost(results),
+		coverage: hasCoverage(results),
+		embedded: hasEmbedded(results),
+	}
+}
+
+// getHeaderRow returns the header columns. The Host column is only added when
+// a hostname was checked, the Coverage column only when a prefix or address
+// range was checked and the Embedded column only when an IPv6 address embeds
+// an IPv4 address.
+func getHeaderRow(results []common.Result) []string {
+	return getColumnsHeaderRow(getOutputColumns(results))
+}
+
+func getColumnsHeaderRow(columns outputColumns) []string {
+	row := make([]string, 0, len(headerOrder)+3)
+	if columns.host {
+		row = append(row, headers["Host"])
+	}
+	for _, key := range headerOrder {
+		row = append(row, headers[key])
+	}
+	if columns.coverage {
+		row = append(row, headers["Coverage"])
+	}
+	if columns.embedded {
+		row = append(row, headers["Embedded"])
+	}
+	return row
+}
+
+func hasHost(results []common.Result) bool {
+	for _, r := range results {
+		if r.Host != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCoverage(results []common.Result) bool {
+	for _, r := range results {
+		if r.Coverage != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEmbedded reports whether any address, including the addresses hostnames
+// resolved to, embeds an IPv4 address.
+func hasEmbedded(results []common.Result) bool {
+	for _, r := range results {
+		if r.Embedded != nil || hasEmbedded(r.Addresses) {
+			return true
+		}
+	}
+	return false
+}
+
+// getResultRow returns the row of a result. When the output has the Coverage
+// column, the rows of single addresses leave it empty.
+func getResultRow(r common.Result, withCoverage bool) []string {
+	row := []string{
+		r.Ip,
+		getProviderString(r),
+		getFieldString(r.Range.Prefix),
+		getFieldString(r.Range.Region),
+		getFieldString(r.Range.Service),
+	}
+	if withCoverage {
+		row = append(row, getFieldString(string(r.Coverage)))
+	}
+	return row
+}
+
+// getResultRows returns one row per match so overlapping providers are all
+// listed. The match the result reports comes first, so the row chosen by the
+// strategy leads even when it is not the first provider in order.
+func getResultRows(r common.Result, withCoverage bool) [][]string {
+	if len(r.Matches) == 0 {
+		return [][]string{getResultRow(r, withCoverage)}
+	}
+
+	rows := make([][]string, 0, len(r.Matches))
+	for _, match := range winnerFirst(r) {
+		rows = append(rows, getResultRow(common.Result{
+			Ip:       r.Ip,
+			Provider: match.Provider,
+			Range:    match.Range,
+			Coverage: match.Coverage,
+		}, withCoverage))
+	}
+	return rows
+}
+
+// winnerFirst returns the matches of the result with the reported match moved
+// to the front and the others kept in provider order.
+func winnerFirst(r common.Result) []common.Match {
+	winner := slices.IndexFunc(r.Matches, func(match common.Match) bool {
+		return match.Provider == r.Provider && match.Range.Prefix == r.Range.Prefix
+	})
+	if winner <= 0 {
+		return r.Matches
+	}
+
+	matches := make([]common.Match, 0, len(r.Matches))
+	matches = append(matches, r.Matches[winner])
+	matches = append(matches, r.Matches[:winner]...)
+	return append(matches, r.Matches[winner+1:]...)
+}
+
+// getOutputRows returns the rows of a result with the Host column prepended
+// when the output has one. A hostname lists one group of rows per resolved address.
+func getOutputRows(r common.Result, columns outputColumns) [][]string {
+	if !columns.host {
+		return getAddressRows(r, columns)
+	}
+	if r.Host == "" {
+		return prependColumn("-", getAddressRows(r, columns))
+	}
+	if len(r.Addresses) == 0 {
+		return prependColumn(r.Host, getAddressRows(common.Result{Ip: "-", Error: r.Error}, columns))
+	}
+
+	var rows [][]string
+	for _, address := range r.Addresses {
+		rows = append(rows, prependColumn(r.Host, getAddressRows(address, columns))...)
+	}
+	return rows
+}
+
+// getAddressRows returns the rows of an address. When the output has the
+// Embedded column, an address that embeds an IPv4 address is followed by the
+// rows of the IPv4 address, labelled with the format it was extracted from.
+func getAddressRows(r common.Result, columns outputColumns) [][]string {
+	if !columns.embedded {
+		return getResultRows(r, columns.coverage)
+	}
+
+	rows := appendColumn(getResultRows(r, columns.coverage), "-")
+	if r.Embedded == nil {
+		return rows
+	}
+	embeddedRows := getResultRows(*r.Embedded, columns.coverage)
+	for _, row := range embeddedRows {
+		row[0] = r.Ip
+	}
+	return append(rows, appendColumn(embeddedRows, r.Embedding+":"+r.Embedded.Ip)...)
+}
+
+func appendColumn(rows [][]string, value string) [][]string {
+	for i, row := range rows {
+		rows[i] = append(row, value)
+	}
+	return rows
+}
+
+func prependColumn(value string, rows [][]string) [][]string {
+	for i, row := range rows {
+		rows[i] = append([]string{value}, row...)
+	}
+	return rows
 }
 
 func printResult(w io.Writer, results []common.Result, flags *common.CloudIpFlag) error {
@@ -40,29 +262,36 @@ func printResult(w io.Writer, results []common.Result, flags *common.CloudIpFlag
 		return printResultAsTable(w, results, flags)
 	case "json":
 		return printResultAsJson(w, results)
+	case "ndjson":
+		return printResultAsNdjson(w, results)
 	default:
-		return fmt.Errorf("invalid output format: %s. Supported formats are: text, table, json", flags.Format)
+		return fmt.Errorf("invalid output format: %s. Supported formats are: text, table, json, ndjson", flags.Format)
 	}
 }
 
 func printResultAsText(w io.Writer, results []common.Result, flags *common.CloudIpFlag) error {
 	if flags.Header {
-		if _, err := fmt.Fprintf(w, "%s%s%s\n", headers["IP"], flags.Delimiter, headers["Provider"]); err != nil {
+		if _, err := fmt.Fprintln(w, strings.Join(getHeaderRow(results), flags.Delimiter)); err != nil {
 			return fmt.Errorf("error writing text result: %w", err)
 		}
 	}
+	columns := getOutputColumns(results)
 	for _, r := range results {
-		if _, err := fmt.Fprintf(w, "%s%s%s\n", r.Ip, flags.Delimiter, getProviderString(r)); err != nil {
-			return fmt.Errorf("error writing text result: %w", err)
+		for _, row := range getOutputRows(r, columns) {
+			if _, err := fmt.Fprintln(w, strings.Join(row, flags.Delimiter)); err != nil {
+				return fmt.Errorf("error writing text result: %w", err)
+			}
 		}
 	}
 	return nil
 }
-func printResultAsTable(w io.Writer, results []common.Result, flags *common.CloudIpFlag) error {
-	table := tablewriter.NewTable(w,
+
+// newTable returns a borderless table whose columns are separated by the delimiter.
+func newTable(w io.Writer, delimiter string) *tablewriter.Table {
+	return tablewriter.NewTable(w,
 		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
 			Borders:  tw.Border{Left: tw.Off, Right: tw.Off, Top: tw.Off, Bottom: tw.Off},
-			Symbols:  tw.NewSymbolCustom("delim").WithColumn(flags.Delimiter),
+			Symbols:  tw.NewSymbolCustom("delim").WithColumn(delimiter),
 			Settings: tw.Settings{Lines: tw.LinesNone},
 		})),
 		tablewriter.WithHeaderAlignment(tw.AlignLeft),
@@ -70,10 +299,17 @@ func printResultAsTable(w io.Writer, results []common.Result, flags *common.Clou
 		tablewriter.WithHeaderAutoFormat(tw.Off),
 		tablewriter.WithPadding(tw.PaddingNone),
 	)
+}
 
-	table.Header(headers["IP"], headers["Provider"])
+func printResultAsTable(w io.Writer, results []common.Result, flags *common.CloudIpFlag) error {
+	table := newTable(w, flags.Delimiter)
+
+	table.Header(getHeaderRow(results))
+	columns := getOutputColumns(results)
 	for _, r := range results {
-		table.Append(r.Ip, getProviderString(r))
+		for _, row := range getOutputRows(r, columns) {
+			table.Append(row)
+		}
 	}
 	if err := table.Render(); err != nil {
 		return fmt.Errorf("error writing table result: %w", err)
@@ -82,14 +318,9 @@ func printResultAsTable(w io.Writer, results []common.Result, flags *common.Clou
 }
 
 func printResultAsJson(w io.Writer, results []common.Result) error {
-	resultSlice := make([]jsonResult, 0, len(results))
+	resultSlice := make([]any, 0, len(results))
 	for _, r := range results {
-		result := jsonResult{
-			IP:       r.Ip,
-			Provider: getJSONProviderString(r),
-			Error:    getErrorString(r),
-		}
-		resultSlice = append(resultSlice, result)
+		resultSlice = append(resultSlice, getJSONValue(r))
 	}
 	bytes, err := json.Marshal(resultSlice)
 	if err != nil {
@@ -101,6 +332,80 @@ func printResultAsJson(w io.Writer, results []common.Result) error {
 	return nil
 }
 
+// printResultAsNdjson writes one JSON object per line.
+func printResultAsNdjson(w io.Writer, results []common.Result) error {
+	for _, r := range results {
+		bytes, err := json.Marshal(getJSONValue(r))
+		if err != nil {
+			return fmt.Errorf("error converting result to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(bytes)); err != nil {
+			return fmt.Errorf("error writing NDJSON result: %w", err)
+		}
+	}
+	return nil
+}
+
+// getJSONValue returns the JSON form of a result; hostnames group the results
+// of their addresses.
+func getJSONValue(r common.Result) any {
+	if r.Host == "" {
+		return getJSONResult(r)
+	}
+
+	hostResult := jsonHostResult{
+		Host:      r.Host,
+		Addresses: make([]jsonResult, 0, len(r.Addresses)),
+		Error:     getErrorString(r),
+	}
+	for _, address := range r.Addresses {
+		hostResult.Addresses = append(hostResult.Addresses, getJSONResult(address))
+	}
+	return hostResult
+}
+
+func getJSONResult(r common.Result) jsonResult {
+	return jsonResult{
+		IP:         r.Ip,
+		Provider:   getJSONProviderString(r),
+		Prefix:     r.Range.Prefix,
+		Region:     r.Range.Region,
+		Service:    r.Range.Service,
+		Attributes: r.Range.Attributes,
+		Matches:    getJSONMatches(r),
+		Strategy:   string(r.Strategy),
+		Coverage:   string(r.Coverage),
+		Embedded:   getJSONEmbedded(r),
+		Error:      getErrorString(r),
+	}
+}
+
+func getJSONEmbedded(r common.Result) *jsonEmbedded {
+	if r.Embedded == nil {
+		return nil
+	}
+	return &jsonEmbedded{Embedding: r.Embedding, jsonResult: getJSONResult(*r.Embedded)}
+}
+
+func getJSONMatches(r common.Result) []jsonMatch {
+	if len(r.Matches) == 0 {
+		return nil
+	}
+
+	matches := make([]jsonMatch, 0, len(r.Matches))
+	for _, match := range r.Matches {
+		matches = append(matches, jsonMatch{
+			Provider:   string(match.Provider),
+			Prefix:     match.Range.Prefix,
+			Region:     match.Range.Region,
+			Service:    match.Range.Service,
+			Attributes: match.Range.Attributes,
+			Coverage:   string(match.Coverage),
+		})
+	}
+	return matches
+}
+
 func getJSONProviderString(r common.Result) string {
 	if r.Error != nil {
 		return "error"
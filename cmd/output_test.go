@@ -59,12 +59,12 @@ func TestPrintResultDispatchesFormat(t *testing.T) {
 		{
 			name:     "dispatches to text",
 			format:   "text",
-			expected: "1.2.3.4 aws",
+			expected: "1.2.3.4 aws - - -",
 		},
 		{
 			name:     "dispatches to json",
 			format:   "json",
-			expected: `[{"ip":"1.2.3.4","provider":"aws","error":""}]`,
+			expected: `[{"ip":"1.2.3.4","provider":"aws","prefix":"","region":"","service":"","error":""}]`,
 		},
 	}
 
@@ -138,7 +138,7 @@ func TestPrintResultAsText(t *testing.T) {
 				{Ip: "1.2.3.4", Provider: common.AWS},
 				{Ip: "5.6.7.8", Provider: ""},
 			},
-			expected: []string{"1.2.3.4 aws", "5.6.7.8 unknown"},
+			expected: []string{"1.2.3.4 aws - - -", "5.6.7.8 unknown - - -"},
 		},
 		{
 			name:      "with header",
@@ -147,7 +147,7 @@ func TestPrintResultAsText(t *testing.T) {
 			results: []common.Result{
 				{Ip: "1.2.3.4", Provider: common.AWS},
 			},
-			expected: []string{"IP Provider", "1.2.3.4 aws"},
+			expected: []string{"IP Provider Prefix Region Service", "1.2.3.4 aws - - -"},
 		},
 		{
 			name:      "custom delimiter",
@@ -155,7 +155,7 @@ func TestPrintResultAsText(t *testing.T) {
 			results: []common.Result{
 				{Ip: "1.2.3.4", Provider: common.GCP},
 			},
-			expected: []string{"1.2.3.4,gcp"},
+			expected: []string{"1.2.3.4,gcp,-,-,-"},
 		},
 		{
 			name:      "header uses delimiter",
@@ -164,7 +164,36 @@ func TestPrintResultAsText(t *testing.T) {
 			results: []common.Result{
 				{Ip: "1.2.3.4", Provider: common.AWS},
 			},
-			expected: []string{"IP,Provider", "1.2.3.4,aws"},
+			expected: []string{"IP,Provider,Prefix,Region,Service", "1.2.3.4,aws,-,-,-"},
+		},
+		{
+			name:      "matched range",
+			delimiter: " ",
+			results: []common.Result{
+				{
+					Ip:       "3.5.140.1",
+					Provider: common.AWS,
+					Range:    common.RangeInfo{Prefix: "3.5.140.0/22", Region: "ap-northeast-2", Service: "S3"},
+				},
+				{Ip: "bad-ip", Error: fmt.Errorf("error parsing IP: bad-ip")},
+			},
+			expected: []string{"3.5.140.1 aws 3.5.140.0/22 ap-northeast-2 S3", "bad-ip ERROR - - -"},
+		},
+		{
+			name:      "all matches",
+			delimiter: " ",
+			results: []common.Result{
+				{
+					Ip:       "104.16.0.1",
+					Provider: common.Cloudflare,
+					Range:    common.RangeInfo{Prefix: "104.16.0.0/13"},
+					Matches: []common.Match{
+						{Provider: common.Cloudflare, Range: common.RangeInfo{Prefix: "104.16.0.0/13"}},
+						{Provider: common.AWS, Range: common.RangeInfo{Prefix: "104.16.0.0/24", Region: "us-east-1", Service: "EC2"}},
+					},
+				},
+			},
+			expected: []string{"104.16.0.1 cloudflare 104.16.0.0/13 - -", "104.16.0.1 aws 104.16.0.0/24 us-east-1 EC2"},
 		},
 	}
 
@@ -318,3 +347,308 @@ func TestPrintResultAsJson(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintResultAsJsonIncludesRange(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:       "20.60.0.1",
+			Provider: common.Azure,
+			Range: common.RangeInfo{
+				Prefix:     "20.60.0.0/16",
+				Region:     "eastus",
+				Service:    "AzureStorage",
+				Attributes: map[string]string{"tag": "Storage.EastUS"},
+			},
+		},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []jsonResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(parsed))
+	}
+
+	got := parsed[0]
+	if got.Prefix != "20.60.0.0/16" || got.Region != "eastus" || got.Service != "AzureStorage" {
+		t.Errorf("unexpected range fields: %+v", got)
+	}
+	if got.Attributes["tag"] != "Storage.EastUS" {
+		t.Errorf("expected tag attribute, got %v", got.Attributes)
+	}
+}
+
+func TestPrintResultAsTextPutsStrategyWinnerFirst(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:       "104.16.0.1",
+			Provider: common.Cloudflare,
+			Range:    common.RangeInfo{Prefix: "104.16.0.0/13"},
+			Strategy: common.StrategyLongestPrefix,
+			Matches: []common.Match{
+				{Provider: common.AWS, Range: common.RangeInfo{Prefix: "104.0.0.0/8", Region: "us-east-1", Service: "AMAZON"}},
+				{Provider: common.GCP, Range: common.RangeInfo{Prefix: "104.16.0.0/12"}},
+				{Provider: common.Cloudflare, Range: common.RangeInfo{Prefix: "104.16.0.0/13"}},
+			},
+		},
+	}
+
+	output := new(bytes.Buffer)
+	flags := &common.CloudIpFlag{Delimiter: " "}
+	if err := printResultAsText(output, results, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "104.16.0.1 cloudflare 104.16.0.0/13 - -\n" +
+		"104.16.0.1 aws 104.0.0.0/8 us-east-1 AMAZON\n" +
+		"104.16.0.1 gcp 104.16.0.0/12 - -\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestPrintResultAsJsonIncludesStrategy(t *testing.T) {
+	results := []common.Result{
+		{Ip: "104.16.0.1", Provider: common.Cloudflare, Strategy: common.StrategyLongestPrefix},
+		{Ip: "5.6.7.8"},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	if parsed[0]["strategy"] != "longest-prefix" {
+		t.Errorf("expected strategy 'longest-prefix', got %q", parsed[0]["strategy"])
+	}
+	if _, ok := parsed[1]["strategy"]; ok {
+		t.Errorf("expected strategy to be omitted for unmatched result, got %v", parsed[1])
+	}
+}
+
+func TestPrintResultAsJsonIncludesMatches(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:       "104.16.0.1",
+			Provider: common.Cloudflare,
+			Range:    common.RangeInfo{Prefix: "104.16.0.0/13"},
+			Matches: []common.Match{
+				{Provider: common.Cloudflare, Range: common.RangeInfo{Prefix: "104.16.0.0/13"}},
+				{Provider: common.AWS, Range: common.RangeInfo{Prefix: "104.16.0.0/24", Service: "EC2"}},
+			},
+		},
+		{Ip: "5.6.7.8"},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []jsonResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	if len(parsed[0].Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %v", parsed[0].Matches)
+	}
+	if parsed[0].Matches[1].Provider != "aws" || parsed[0].Matches[1].Prefix != "104.16.0.0/24" {
+		t.Errorf("unexpected second match: %+v", parsed[0].Matches[1])
+	}
+	if strings.Contains(output.String(), `"ip":"5.6.7.8","provider":"unknown","prefix":"","region":"","service":"","matches"`) {
+		t.Errorf("expected matches to be omitted for unmatched result, got %q", output.String())
+	}
+}
+
+func TestPrintResultAsTextPadsCoverageOfMixedInputs(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:       "13.32.0.0/15",
+			Provider: common.AWS,
+			Range:    common.RangeInfo{Prefix: "13.32.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"},
+			Coverage: common.CoverageFull,
+			Matches: []common.Match{
+				{Provider: common.AWS, Range: common.RangeInfo{Prefix: "13.32.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}, Coverage: common.CoverageFull},
+				{Provider: common.AWS, Range: common.RangeInfo{Prefix: "13.33.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}, Coverage: common.CoverageFull},
+			},
+		},
+		{Ip: "192.168.0.0/16", Coverage: common.CoverageNone},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	flags := &common.CloudIpFlag{Delimiter: ",", Header: true}
+	if err := printResultAsText(output, results, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "IP,Provider,Prefix,Region,Service,Coverage\n" +
+		"13.32.0.0/15,aws,13.32.0.0/16,GLOBAL,CLOUDFRONT,full\n" +
+		"13.32.0.0/15,aws,13.33.0.0/16,GLOBAL,CLOUDFRONT,full\n" +
+		"192.168.0.0/16,unknown,-,-,-,none\n" +
+		"1.2.3.4,gcp,-,-,-,-\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestPrintResultAsJsonIncludesCoverage(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:       "13.32.0.0/14",
+			Provider: common.AWS,
+			Range:    common.RangeInfo{Prefix: "13.32.0.0/16"},
+			Coverage: common.CoveragePartial,
+			Matches: []common.Match{
+				{Provider: common.AWS, Range: common.RangeInfo{Prefix: "13.32.0.0/16"}, Coverage: common.CoveragePartial},
+			},
+		},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []jsonResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	if parsed[0].Coverage != "partial" || parsed[0].Matches[0].Coverage != "partial" {
+		t.Errorf("expected partial coverage, got %+v", parsed[0])
+	}
+	if strings.Contains(output.String(), `"ip":"1.2.3.4","provider":"gcp","prefix":"","region":"","service":"","coverage"`) {
+		t.Errorf("expected coverage to be omitted for single IP result, got %q", output.String())
+	}
+}
+
+func TestPrintResultAsTextGroupsHostnames(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:   "api.example.com",
+			Host: "api.example.com",
+			Addresses: []common.Result{
+				{Ip: "192.0.2.10", Provider: common.AWS, Range: common.RangeInfo{Prefix: "192.0.2.0/24", Region: "us-east-1", Service: "EC2"}},
+				{Ip: "2001:db8::1"},
+			},
+		},
+		{Ip: "missing.example.com", Host: "missing.example.com", Error: fmt.Errorf("no such host")},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	flags := &common.CloudIpFlag{Delimiter: ",", Header: true}
+	if err := printResultAsText(output, results, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Host,IP,Provider,Prefix,Region,Service\n" +
+		"api.example.com,192.0.2.10,aws,192.0.2.0/24,us-east-1,EC2\n" +
+		"api.example.com,2001:db8::1,unknown,-,-,-\n" +
+		"missing.example.com,-,ERROR,-,-,-\n" +
+		"-,1.2.3.4,gcp,-,-,-\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestPrintResultAsJsonGroupsHostnames(t *testing.T) {
+	results := []common.Result{
+		{
+			Ip:   "api.example.com",
+			Host: "api.example.com",
+			Addresses: []common.Result{
+				{Ip: "192.0.2.10", Provider: common.AWS, Range: common.RangeInfo{Prefix: "192.0.2.0/24"}},
+			},
+		},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []json.RawMessage
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+
+	var host jsonHostResult
+	if err := json.Unmarshal(parsed[0], &host); err != nil {
+		t.Fatalf("host result is not valid JSON: %v", err)
+	}
+	if host.Host != "api.example.com" || len(host.Addresses) != 1 || host.Addresses[0].IP != "192.0.2.10" || host.Addresses[0].Provider != "aws" {
+		t.Errorf("unexpected host result: %+v", host)
+	}
+
+	var ip jsonResult
+	if err := json.Unmarshal(parsed[1], &ip); err != nil {
+		t.Fatalf("IP result is not valid JSON: %v", err)
+	}
+	if ip.IP != "1.2.3.4" || ip.Provider != "gcp" {
+		t.Errorf("unexpected IP result: %+v", ip)
+	}
+}
+
+func TestPrintResultAsTextWithEmbedded(t *testing.T) {
+	embedded := &common.Result{Ip: "54.230.1.1", Provider: common.AWS, Range: common.RangeInfo{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}}
+	results := []common.Result{
+		{Ip: "64:ff9b::36e6:101", Embedded: embedded, Embedding: "nat64"},
+		{Ip: "13.32.0.0/15", Provider: common.AWS, Coverage: common.CoverageFull},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	flags := &common.CloudIpFlag{Delimiter: ",", Header: true}
+	if err := printResultAsText(output, results, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "IP,Provider,Prefix,Region,Service,Coverage,Embedded\n" +
+		"64:ff9b::36e6:101,unknown,-,-,-,-,-\n" +
+		"64:ff9b::36e6:101,aws,54.230.0.0/16,GLOBAL,CLOUDFRONT,-,nat64:54.230.1.1\n" +
+		"13.32.0.0/15,aws,-,-,-,full,-\n" +
+		"1.2.3.4,gcp,-,-,-,-,-\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestPrintResultAsJsonIncludesEmbedded(t *testing.T) {
+	results := []common.Result{
+		{Ip: "2002:36e6:101::1", Embedded: &common.Result{Ip: "54.230.1.1", Provider: common.AWS}, Embedding: "6to4"},
+		{Ip: "1.2.3.4", Provider: common.GCP},
+	}
+
+	output := new(bytes.Buffer)
+	if err := printResultAsJson(output, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []jsonResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	embedded := parsed[0].Embedded
+	if parsed[0].Provider != "unknown" || embedded == nil || embedded.Embedding != "6to4" || embedded.IP != "54.230.1.1" || embedded.Provider != "aws" {
+		t.Errorf("expected embedded 6to4 result, got %+v", parsed[0])
+	}
+	if !strings.Contains(output.String(), `"embedded":{"embedding":"6to4","ip":"54.230.1.1","provider":"aws"`) {
+		t.Errorf("expected the embedded result to be flattened, got %q", output.String())
+	}
+	if parsed[1].Embedded != nil {
+		t.Errorf("expected embedded to be omitted for IPv4 result, got %+v", parsed[1].Embedded)
+	}
+}
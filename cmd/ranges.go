@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type rangesOptions struct {
+	region    string
+	service   string
+	scope     string
+	tag       string
+	ipv4      bool
+	ipv6      bool
+	format    string
+	delimiter string
+}
+
+type jsonRange struct {
+	Prefix     string            `json:"prefix"`
+	Region     string            `json:"region"`
+	Service    string            `json:"service"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+var rangesHeaderRow = []string{"Prefix", "Region", "Service"}
+
+func newRangesCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
+	options := &rangesOptions{}
+	rangesCmd := &cobra.Command{
+		Use:   "ranges PROVIDER",
+		Short: "List the ranges a provider publishes, filtered by region, service, scope or IP version",
+		Long: "Ranges prints the CIDRs a provider publishes. The text format prints one unique prefix per line " +
+			"so the output can be used as an allowlist; table and json include the region, service and attributes.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+
+			ranges, err := loadProviderRanges(checker, args[0])
+			if err != nil {
+				return err
+			}
+			return printRanges(cmd.OutOrStdout(), filterRanges(ranges, options), options)
+		},
+	}
+
+	rangesCmd.Flags().StringVar(&options.region, "region", "", "Only list ranges in the region")
+	rangesCmd.Flags().StringVar(&options.service, "service", "", "Only list ranges of the service")
+	rangesCmd.Flags().StringVar(&options.scope, "scope", "", "Only list ranges with the scope (GCP)")
+	rangesCmd.Flags().StringVar(&options.tag, "tag", "", "Only list ranges with the service tag (Azure, OCI)")
+	rangesCmd.Flags().BoolVar(&options.ipv4, "ipv4", false, "Only list IPv4 ranges")
+	rangesCmd.Flags().BoolVar(&options.ipv6, "ipv6", false, "Only list IPv6 ranges")
+	rangesCmd.MarkFlagsMutuallyExclusive("ipv4", "ipv6")
+	rangesCmd.Flags().StringVarP(&options.format, "format", "f", "text", "Output format (text, table, json)")
+	rangesCmd.Flags().StringVar(&options.delimiter, "delimiter", " ", "Delimiter for the output. Applicable for 'table' format")
+
+	return rangesCmd
+}
+
+// loadProviderRanges returns every range the provider publishes. Providers
+// that cannot load their raw data fall back to their merged ranges.
+func loadProviderRanges(checker *ip.IPChecker, name string) ([]common.RangeInfo, error) {
+	providerType := common.CloudProvider(strings.ToLower(name))
+	p, exists := checker.Provider(providerType)
+	if !exists {
+		var supported []string
+		for _, registered := range checker.Providers() {
+			supported = append(supported, string(registered))
+		}
+		return nil, fmt.Errorf("unknown provider: %s. Supported providers are: %s", name, strings.Join(supported, ", "))
+	}
+
+	if loader, ok := p.(provider.RangeLoader); ok {
+		return loader.LoadRanges()
+	}
+	if lister, ok := p.(provider.RangeLister); ok {
+		if err := p.Initialize(); err != nil {
+			return nil, err
+		}
+		return lister.Ranges(), nil
+	}
+	return nil, fmt.Errorf("provider %s cannot list its ranges", providerType)
+}
+
+type prefixedRange struct {
+	prefix netip.Prefix
+	info   common.RangeInfo
+}
+
+// filterRanges returns the matching ranges in address order. Region, service,
+// scope and tag are compared case-insensitively.
+func filterRanges(ranges []common.RangeInfo, options *rangesOptions) []common.RangeInfo {
+	var matched []prefixedRange
+	for _, info := range ranges {
+		prefix, err := util.ParsePrefix(info.Prefix)
+		if err != nil {
+			continue
+		}
+		if options.ipv4 && !prefix.Addr().Is4() || options.ipv6 && prefix.Addr().Is4() {
+			continue
+		}
+		if !matchesFilter(info.Region, options.region) || !matchesFilter(info.Service, options.service) ||
+			!matchesFilter(info.Attributes["scope"], options.scope) || !matchesFilter(info.Attributes["tag"], options.tag) {
+			continue
+		}
+		matched = append(matched, prefixedRange{prefix: prefix, info: info})
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return util.ComparePrefixes(matched[i].prefix, matched[j].prefix) < 0
+	})
+
+	result := make([]common.RangeInfo, 0, len(matched))
+	for _, entry := range matched {
+		result = append(result, entry.info)
+	}
+	return result
+}
+
+func matchesFilter(value, filter string) bool {
+	return filter == "" || strings.EqualFold(value, filter)
+}
+
+func printRanges(w io.Writer, ranges []common.RangeInfo, options *rangesOptions) error {
+	switch options.format {
+	case "text":
+		seen := make(map[string]bool, len(ranges))
+		for _, info := range ranges {
+			if seen[info.Prefix] {
+				continue
+			}
+			seen[info.Prefix] = true
+			if _, err := fmt.Fprintln(w, info.Prefix); err != nil {
+				return fmt.Errorf("error writing text ranges: %w", err)
+			}
+		}
+		return nil
+	case "table":
+		table := newTable(w, options.delimiter)
+		table.Header(rangesHeaderRow)
+		for _, info := range ranges {
+			table.Append([]string{info.Prefix, getFieldString(info.Region), getFieldString(info.Service)})
+		}
+		if err := table.Render(); err != nil {
+			return fmt.Errorf("error writing table ranges: %w", err)
+		}
+		return nil
+	case "json":
+		jsonRanges := make([]jsonRange, 0, len(ranges))
+		for _, info := range ranges {
+			jsonRanges = append(jsonRanges, jsonRange{
+				Prefix:     info.Prefix,
+				Region:     info.Region,
+				Service:    info.Service,
+				Attributes: info.Attributes,
+			})
+		}
+		bytes, err := json.Marshal(jsonRanges)
+		if err != nil {
+			return fmt.Errorf("error converting ranges to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(bytes)); err != nil {
+			return fmt.Errorf("error writing JSON ranges: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s. Supported formats are: text, table, json", options.format)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// loaderProvider publishes a prefix twice, like AWS does for AMAZON.
+type loaderProvider struct {
+	*provider.BaseProvider
+	ranges []common.RangeInfo
+}
+
+func (p *loaderProvider) LoadRanges() ([]common.RangeInfo, error) {
+	return p.ranges, nil
+}
+
+func executeRanges(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	return executeWithRangeProviders(t, append([]string{"ranges"}, args...)...)
+}
+
+// executeWithRangeProviders runs the root command with AWS and Azure providers
+// that can list their ranges.
+func executeWithRangeProviders(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	aws := &loaderProvider{
+		BaseProvider: provider.NewBaseProvider("AWS", staticDataManager{}, func(*provider.BaseProvider) error { return nil }),
+		ranges: []common.RangeInfo{
+			{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"},
+			{Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"},
+			{Prefix: "2600:9000::/28", Region: "GLOBAL", Service: "CLOUDFRONT"},
+			{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "AMAZON"},
+		},
+	}
+	azure := provider.NewBaseProvider("Azure", staticDataManager{}, func(bp *provider.BaseProvider) error {
+		return bp.AddRange(common.RangeInfo{Prefix: "20.36.0.0/19", Region: "eastus", Attributes: map[string]string{"tag": "AzureFrontDoor.Backend"}})
+	})
+	checker := ip.NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: aws, common.Azure: azure},
+		ip.DefaultProviderOrder,
+	)
+	cmd := NewRootCmd(&common.CloudIpFlag{}, checker)
+
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestRangesListsPrefixesInAddressOrder(t *testing.T) {
+	stdout, err := executeRanges(t, "AWS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "3.5.0.0/19\n54.230.0.0/16\n2600:9000::/28\n"
+	if stdout != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout)
+	}
+}
+
+func TestRangesFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"service and IPv6", []string{"aws", "--service", "cloudfront", "--ipv6"}, "2600:9000::/28\n"},
+		{"IPv4 only", []string{"aws", "--ipv4"}, "3.5.0.0/19\n54.230.0.0/16\n"},
+		{"region", []string{"aws", "--region", "us-east-1"}, "3.5.0.0/19\n"},
+		{"service published twice", []string{"aws", "--service", "AMAZON"}, "54.230.0.0/16\n"},
+		{"Azure tag from merged ranges", []string{"azure", "--tag", "AzureFrontDoor.Backend"}, "20.36.0.0/19\n"},
+		{"no match", []string{"aws", "--region", "mars-1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := executeRanges(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stdout != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, stdout)
+			}
+		})
+	}
+}
+
+func TestRangesAsJson(t *testing.T) {
+	stdout, err := executeRanges(t, "aws", "--format", "json", "--ipv4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []jsonRange
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, stdout)
+	}
+	if len(parsed) != 3 || parsed[1].Service != "CLOUDFRONT" || parsed[2].Service != "AMAZON" {
+		t.Errorf("unexpected ranges: %+v", parsed)
+	}
+}
+
+func TestRangesRejectsInvalidArguments(t *testing.T) {
+	if _, err := executeRanges(t, "oracle"); err == nil || !strings.Contains(err.Error(), "aws, azure") {
+		t.Errorf("expected unknown provider error listing providers, got %v", err)
+	}
+	if _, err := executeRanges(t, "aws", "--ipv4", "--ipv6"); err == nil {
+		t.Error("expected error for --ipv4 with --ipv6")
+	}
+	if _, err := executeRanges(t, "aws", "--format", "csv"); err == nil {
+		t.Error("expected error for invalid format")
+	}
+}
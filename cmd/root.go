@@ -13,19 +13,31 @@ var Version string
 
 func NewRootCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:           common.AppName,
+		Use:           common.AppName + " [IP|CIDR|RANGE|HOSTNAME|-]...",
 		Short:         fmt.Sprintf("%s is a CLI tool for identifying whether an IP address belongs to a major cloud provider (e.g., AWS, GCP).", common.AppName),
 		Args:          cobra.ArbitraryArgs,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			common.SetVerbose(flags.Verbose)
-			checker.SetUpdatePolicy(common.UpdatePolicy{
-				NoUpdate: flags.NoUpdate,
-				TTL:      common.DefaultUpdateCheckTTL,
-			})
+			if err := configureChecker(flags, checker); err != nil {
+				return err
+			}
+			checker, err := checkerAsOf(flags, checker)
+			if err != nil {
+				return err
+			}
+			if isStreamInput(flags, args) {
+				failed, err := streamResults(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr(), flags, checker, args)
+				if err != nil {
+					return err
+				}
+				if failed {
+					return errors.New("one or more IP checks failed")
+				}
+				return nil
+			}
 			result := checker.Check(args)
-			if err := printResult(cmd.OutOrStdout(), result, flags); err != nil {
+			if err := printResults(cmd.OutOrStdout(), result, flags); err != nil {
 				return err
 			}
 			if !hasResultError(result) {
@@ -46,18 +58,68 @@ func NewRootCmd(flags *common.CloudIpFlag, checker *ip.IPChecker) *cobra.Command
 	}
 
 	rootCmd.AddCommand(versionCmd)
-	rootCmd.Flags().StringVarP(&flags.Format, "format", "f", "text", "Output format (text, table, json)")
+	rootCmd.AddCommand(newAnnotateCmd(flags, checker))
+	rootCmd.AddCommand(newRangesCmd(flags, checker))
+	rootCmd.AddCommand(newExportCmd(flags, checker))
+	rootCmd.AddCommand(newCidrCmd(flags, checker))
+	rootCmd.AddCommand(newDiffCmd(flags, checker))
+	rootCmd.AddCommand(newHistoryCmd(flags, checker))
+	rootCmd.Flags().StringVarP(&flags.Format, "format", "f", "text", "Output format (text, table, json, ndjson)")
+	rootCmd.Flags().StringVarP(&flags.Input, "input", "i", "", "Read newline-separated addresses from a file ('-' for stdin)")
 	rootCmd.Flags().BoolVar(&flags.Header, "header", false, "Print header in the output. Only applicable for 'text' format")
 	rootCmd.Flags().StringVar(&flags.Delimiter, "delimiter", " ", "Delimiter for the output. Applicable for 'text' and 'table' format")
-	rootCmd.Flags().BoolVar(&flags.NoUpdate, "no-update", false, "Use local provider data without checking for updates")
-	rootCmd.Flags().BoolVarP(&flags.Verbose, "verbose", "v", false, "Print verbose output")
+	rootCmd.Flags().BoolVar(&flags.Summary, "summary", false, "Print counts per provider, region and service instead of one line per IP")
+	rootCmd.Flags().BoolVar(&flags.All, "all", false, "Report every provider that matches instead of the first one")
+	rootCmd.Flags().StringVar(&flags.Resolver, "resolver", "", "DNS server (host:port) used to resolve hostnames. Uses the system resolver when empty")
+	rootCmd.Flags().StringVar(&flags.AsOf, "as-of", "", "Check against the archived provider data that was current at the date (YYYY-MM-DD or RFC 3339)")
+
+	rootCmd.PersistentFlags().StringVar(&flags.Strategy, "strategy", string(common.StrategyOrdered), "Strategy for choosing between matching providers (ordered, longest-prefix)")
+	rootCmd.PersistentFlags().BoolVar(&flags.NoUpdate, "no-update", false, "Use local provider data without checking for updates")
+	rootCmd.PersistentFlags().BoolVarP(&flags.Verbose, "verbose", "v", false, "Print verbose output")
 
 	return rootCmd
 }
 
+// printResults prints the results, or their summary with --summary.
+func printResults(w io.Writer, results []common.Result, flags *common.CloudIpFlag) error {
+	if !flags.Summary {
+		return printResult(w, results, flags)
+	}
+
+	s := newSummary()
+	for _, result := range results {
+		s.Add(result)
+	}
+	return printSummary(w, s, flags)
+}
+
+// configureChecker applies the flags shared by the commands that classify addresses.
+func configureChecker(flags *common.CloudIpFlag, checker *ip.IPChecker) error {
+	common.SetVerbose(flags.Verbose)
+	strategy, err := common.ParseMatchStrategy(flags.Strategy)
+	if err != nil {
+		return err
+	}
+	if err := checker.LoadProviders(); err != nil {
+		return err
+	}
+	checker.SetUpdatePolicy(common.UpdatePolicy{
+		NoUpdate: flags.NoUpdate,
+		TTL:      common.DefaultUpdateCheckTTL,
+	})
+	resolver, err := ip.NewResolver(flags.Resolver)
+	if err != nil {
+		return err
+	}
+	checker.SetResolver(resolver)
+	checker.SetMatchAll(flags.All)
+	checker.SetStrategy(strategy)
+	return nil
+}
+
 func hasResultError(results []common.Result) bool {
 	for _, result := range results {
-		if result.Error != nil {
+		if result.Error != nil || hasResultError(result.Addresses) {
 			return true
 		}
 	}
@@ -66,6 +128,7 @@ func hasResultError(results []common.Result) bool {
 
 func printResultErrors(w io.Writer, results []common.Result) {
 	for _, result := range results {
+		printResultErrors(w, result.Addresses)
 		if result.Error == nil {
 			continue
 		}
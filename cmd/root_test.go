@@ -3,7 +3,10 @@ package cmd
 import (
 	"bytes"
 	"cloudip/common"
+	"cloudip/ip"
+	"cloudip/ip/provider"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -39,6 +42,28 @@ func TestVersionCmdRejectsArgs(t *testing.T) {
 	}
 }
 
+func TestProviderConfigErrorOnlyFailsChecks(t *testing.T) {
+	checker := ip.NewIPChecker(nil, nil)
+	checker.SetProviderLoader(func() (map[common.CloudProvider]provider.CloudProvider, []common.CloudProvider, error) {
+		return nil, nil, errors.New("invalid providers.json")
+	})
+
+	cmd := NewRootCmd(&common.CloudIpFlag{}, checker)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"version"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version error = %v, want the config left unread", err)
+	}
+
+	cmd = NewRootCmd(&common.CloudIpFlag{}, checker)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{"8.8.8.8"})
+	if err := cmd.Execute(); err == nil || err.Error() != "invalid providers.json" {
+		t.Errorf("check error = %v, want the config error", err)
+	}
+}
+
 func TestRootCmdUsesConfiguredOutputWriter(t *testing.T) {
 	cmd, _ := newTestCmd(t)
 	stdout := new(bytes.Buffer)
@@ -51,7 +76,7 @@ func TestRootCmdUsesConfiguredOutputWriter(t *testing.T) {
 	}
 
 	got := strings.TrimSpace(stdout.String())
-	if got != "8.8.8.8 unknown" {
+	if got != "8.8.8.8 unknown - - -" {
 		t.Errorf("expected configured output writer to receive result, got %q", got)
 	}
 }
@@ -65,11 +90,14 @@ func TestFlagDefaults(t *testing.T) {
 		flag     string
 		expected string
 	}{
+		{"all", "all", "false"},
+		{"as-of", "as-of", ""},
 		{"format", "format", "text"},
 		{"delimiter", "delimiter", " "},
 		{"header", "header", "false"},
-		{"no-update", "no-update", "false"},
-		{"verbose", "verbose", "false"},
+		{"input", "input", ""},
+		{"resolver", "resolver", ""},
+		{"summary", "summary", "false"},
 	}
 
 	for _, tt := range tests {
@@ -85,6 +113,36 @@ func TestFlagDefaults(t *testing.T) {
 	}
 }
 
+func TestSharedFlagsArePersistent(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	defaults := map[string]string{
+		"no-update": "false",
+		"strategy":  "ordered",
+		"verbose":   "false",
+	}
+
+	for name, expected := range defaults {
+		f := cmd.PersistentFlags().Lookup(name)
+		if f == nil {
+			t.Fatalf("persistent flag '%s' not found", name)
+		}
+		if f.DefValue != expected {
+			t.Errorf("expected default of '%s' to be '%s', got '%s'", name, expected, f.DefValue)
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		for name := range defaults {
+			if sub.InheritedFlags().Lookup(name) == nil {
+				t.Errorf("%s does not inherit '%s'", sub.Name(), name)
+			}
+			if sub.LocalFlags().Lookup(name) != nil {
+				t.Errorf("%s registers its own '%s'", sub.Name(), name)
+			}
+		}
+	}
+}
+
 func TestFlagBinding(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -100,6 +158,24 @@ func TestFlagBinding(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "resolver flag",
+			args: []string{"--resolver", "127.0.0.1:53"},
+			verify: func(t *testing.T, flags *common.CloudIpFlag) {
+				if flags.Resolver != "127.0.0.1:53" {
+					t.Errorf("expected Flags.Resolver '127.0.0.1:53', got '%s'", flags.Resolver)
+				}
+			},
+		},
+		{
+			name: "all flag",
+			args: []string{"--all"},
+			verify: func(t *testing.T, flags *common.CloudIpFlag) {
+				if !flags.All {
+					t.Error("expected Flags.All to be true")
+				}
+			},
+		},
 		{
 			name: "format flag",
 			args: []string{"--format", "json"},
@@ -127,6 +203,15 @@ func TestFlagBinding(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "as-of flag",
+			args: []string{"--as-of", "2026-03-01"},
+			verify: func(t *testing.T, flags *common.CloudIpFlag) {
+				if flags.AsOf != "2026-03-01" {
+					t.Errorf("expected Flags.AsOf '2026-03-01', got '%s'", flags.AsOf)
+				}
+			},
+		},
 		{
 			name: "no-update flag",
 			args: []string{"--no-update"},
@@ -136,12 +221,21 @@ func TestFlagBinding(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "strategy flag",
+			args: []string{"--strategy", "longest-prefix"},
+			verify: func(t *testing.T, flags *common.CloudIpFlag) {
+				if flags.Strategy != "longest-prefix" {
+					t.Errorf("expected Flags.Strategy 'longest-prefix', got '%s'", flags.Strategy)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cmd, flags := newTestCmd(t)
-			err := cmd.Flags().Parse(tt.args)
+			err := cmd.ParseFlags(tt.args)
 			if err != nil {
 				t.Fatalf("unexpected error parsing flags: %v", err)
 			}
@@ -213,3 +307,31 @@ func TestRootCmdJSONErrorOutputIsValidJSON(t *testing.T) {
 		t.Fatalf("stderr should not include usage for result errors, got %q", stderr.String())
 	}
 }
+
+func TestRootCmdRejectsInvalidStrategy(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--strategy", "random", "8.8.8.8"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for invalid strategy")
+	}
+	if !strings.Contains(err.Error(), "random") {
+		t.Fatalf("expected error to mention 'random', got: %v", err)
+	}
+}
+
+func TestRootCmdRejectsInvalidResolver(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--resolver", "127.0.0.1", "8.8.8.8"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for resolver address without port")
+	}
+	if !strings.Contains(err.Error(), "127.0.0.1") {
+		t.Fatalf("expected error to mention '127.0.0.1', got: %v", err)
+	}
+}
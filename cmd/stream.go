@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"cloudip/common"
+	"cloudip/ip"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// stdinInput is the argument or --input value that reads addresses from stdin.
+const stdinInput = "-"
+
+// resultWriter writes results one at a time.
+type resultWriter interface {
+	Write(result common.Result) error
+	Close() error
+}
+
+// newResultWriter returns a writer that streams text and NDJSON output. The
+// table and JSON formats need every result before printing, so they are
+// collected and printed on Close. Summaries only keep the counts.
+func newResultWriter(w io.Writer, flags *common.CloudIpFlag) (resultWriter, error) {
+	if flags.Summary {
+		return &summaryResultWriter{w: w, flags: flags, summary: newSummary()}, nil
+	}
+
+	switch flags.Format {
+	case "text":
+		writer := &textResultWriter{w: w, delimiter: flags.Delimiter}
+		if flags.Header {
+			// The header is printed before any input is read, so it has every
+			// optional column and rows fill the ones they do not use with "-".
+			writer.columns = &outputColumns{host: true, coverage: true, embedded: true}
+			if _, err := fmt.Fprintln(w, strings.Join(getColumnsHeaderRow(*writer.columns), flags.Delimiter)); err != nil {
+				return nil, fmt.Errorf("error writing text result: %w", err)
+			}
+		}
+		return writer, nil
+	case "ndjson":
+		return &ndjsonResultWriter{w: w}, nil
+	case "table", "json":
+		return &bufferedResultWriter{w: w, flags: flags}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format: %s. Supported formats are: text, table, json, ndjson", flags.Format)
+	}
+}
+
+type textResultWriter struct {
+	w         io.Writer
+	delimiter string
+	columns   *outputColumns // Columns of every row, or nil to fit each result
+}
+
+// Write prints the rows of the result. Without fixed columns, the Host,
+// Coverage and Embedded columns are only present on the rows of the inputs
+// that need them.
+func (writer *textResultWriter) Write(result common.Result) error {
+	columns := getOutputColumns([]common.Result{result})
+	if writer.columns != nil {
+		columns = *writer.columns
+	}
+	for _, row := range getOutputRows(result, columns) {
+		if _, err := fmt.Fprintln(writer.w, strings.Join(row, writer.delimiter)); err != nil {
+			return fmt.Errorf("error writing text result: %w", err)
+		}
+	}
+	return nil
+}
+
+func (writer *textResultWriter) Close() error {
+	return nil
+}
+
+type ndjsonResultWriter struct {
+	w io.Writer
+}
+
+func (writer *ndjsonResultWriter) Write(result common.Result) error {
+	return printResultAsNdjson(writer.w, []common.Result{result})
+}
+
+func (writer *ndjsonResultWriter) Close() error {
+	return nil
+}
+
+type bufferedResultWriter struct {
+	w       io.Writer
+	flags   *common.CloudIpFlag
+	results []common.Result
+}
+
+func (writer *bufferedResultWriter) Write(result common.Result) error {
+	writer.results = append(writer.results, result)
+	return nil
+}
+
+func (writer *bufferedResultWriter) Close() error {
+	return printResult(writer.w, writer.results, writer.flags)
+}
+
+// isStreamInput reports whether addresses are read from stdin or a file.
+func isStreamInput(flags *common.CloudIpFlag, args []string) bool {
+	return flags.Input != "" || slices.Contains(args, stdinInput)
+}
+
+// scanInputs calls fn for every address in a newline-separated list. Blank
+// lines and everything after a '#' are ignored.
+func scanInputs(r io.Reader, fn func(input string) error) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading input: %w", err)
+	}
+	return nil
+}
+
+// streamResults checks the positional addresses, stdin ("-") and the --input
+// file in that order, writing every result as soon as it is classified. It
+// reports whether any check failed; failures are printed to stderr as they occur.
+func streamResults(stdin io.Reader, stdout, stderr io.Writer, flags *common.CloudIpFlag, checker *ip.IPChecker, args []string) (bool, error) {
+	writer, err := newResultWriter(stdout, flags)
+	if err != nil {
+		return false, err
+	}
+
+	failed := false
+	check := func(input string) error {
+		result := []common.Result{checker.CheckInput(input)}
+		if hasResultError(result) {
+			failed = true
+			printResultErrors(stderr, result)
+		}
+		return writer.Write(result[0])
+	}
+
+	for _, arg := range args {
+		if arg == stdinInput {
+			err = scanInputs(stdin, check)
+		} else {
+			err = check(arg)
+		}
+		if err != nil {
+			return failed, err
+		}
+	}
+
+	if flags.Input != "" {
+		if err := scanInputFile(stdin, flags.Input, check); err != nil {
+			return failed, err
+		}
+	}
+	return failed, writer.Close()
+}
+
+func scanInputFile(stdin io.Reader, path string, fn func(input string) error) error {
+	if path == stdinInput {
+		return scanInputs(stdin, fn)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening input file: %w", err)
+	}
+	defer file.Close()
+	return scanInputs(file, fn)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanInputsSkipsCommentsAndBlankLines(t *testing.T) {
+	input := "# header comment\n8.8.8.8\n\n   \n1.1.1.1 # resolver\n\t10.0.0.0/8\n"
+
+	var got []string
+	err := scanInputs(strings.NewReader(input), func(value string) error {
+		got = append(got, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"8.8.8.8", "1.1.1.1", "10.0.0.0/8"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestRootCmdReadsStdin(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetIn(strings.NewReader("8.8.8.8\n# skipped\n1.1.1.1\n"))
+	cmd.SetArgs([]string{"9.9.9.9", "-"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "9.9.9.9 unknown - - -\n8.8.8.8 unknown - - -\n1.1.1.1 unknown - - -\n"
+	if stdout.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+}
+
+func TestRootCmdReadsInputFileAsNdjson(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ips.txt")
+	if err := os.WriteFile(path, []byte("8.8.8.8\nbad-ip\n"), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	cmd, _ := newTestCmd(t)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"--input", path, "--format", "ndjson"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected command error for invalid IP")
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 NDJSON lines, got %q", stdout.String())
+	}
+	var first, second map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("line is not valid JSON: %v, line: %q", err, lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("line is not valid JSON: %v, line: %q", err, lines[1])
+	}
+	if first["ip"] != "8.8.8.8" || first["provider"] != "unknown" {
+		t.Errorf("unexpected first line: %v", first)
+	}
+	if second["ip"] != "bad-ip" || second["provider"] != "error" {
+		t.Errorf("unexpected second line: %v", second)
+	}
+	if !strings.Contains(stderr.String(), "bad-ip: error parsing IP: bad-ip") {
+		t.Errorf("expected stderr to include detailed error, got %q", stderr.String())
+	}
+}
+
+func TestRootCmdReportsMissingInputFile(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--input", filepath.Join(t.TempDir(), "missing.txt")})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "error opening input file") {
+		t.Fatalf("expected input file error, got %v", err)
+	}
+}
+
+func TestRootCmdCollectsJSONFromStdin(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetIn(strings.NewReader("8.8.8.8\n1.1.1.1\n"))
+	cmd.SetArgs([]string{"--format", "json", "-"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed []map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout.String())), &parsed); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v, stdout: %q", err, stdout.String())
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 JSON rows, got %v", parsed)
+	}
+}
+
+func TestRootCmdStreamsResultsBeforeInputEnds(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	cmd, _ := newTestCmd(t)
+	cmd.SetIn(stdinReader)
+	cmd.SetOut(stdoutWriter)
+	cmd.SetArgs([]string{"--format", "ndjson", "-"})
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Execute()
+		stdoutWriter.Close()
+		done <- err
+	}()
+
+	output := bufio.NewReader(stdoutReader)
+	for _, address := range []string{"8.8.8.8", "1.1.1.1"} {
+		if _, err := io.WriteString(stdinWriter, address+"\n"); err != nil {
+			t.Fatalf("failed to write stdin: %v", err)
+		}
+		line, err := output.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read streamed result: %v", err)
+		}
+		if !strings.Contains(line, `"ip":"`+address+`"`) {
+			t.Fatalf("expected result for %s, got %q", address, line)
+		}
+	}
+
+	stdinWriter.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRootCmdStreamsTextHeaderWithEveryColumn(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetIn(strings.NewReader("8.8.8.8\n10.0.0.0/8\n64:ff9b::808:808\n"))
+	cmd.SetArgs([]string{"--header", "--delimiter", ",", "-"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Host,IP,Provider,Prefix,Region,Service,Coverage,Embedded\n" +
+		"-,8.8.8.8,unknown,-,-,-,-,-\n" +
+		"-,10.0.0.0/8,unknown,-,-,-,none,-\n" +
+		"-,64:ff9b::808:808,unknown,-,-,-,-,-\n" +
+		"-,64:ff9b::808:808,unknown,-,-,-,-,nat64:8.8.8.8\n"
+	if stdout.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+}
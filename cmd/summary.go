@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"cloudip/common"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var summaryHeaderRow = []string{"Provider", "Region", "Service", "Count", "Percent"}
+
+// summaryAll marks the provider total rows in the text and table summaries.
+const summaryAll = "*"
+
+// summary counts results per provider and per region and service. Hostnames
+// count once per resolved address, and an unknown IPv6 address that embeds an
+// IPv4 address counts as the IPv4 address.
+type summary struct {
+	total     int
+	unknown   int
+	errors    int
+	providers map[common.CloudProvider]*providerSummary
+}
+
+type providerSummary struct {
+	count  int
+	groups map[summaryGroup]int
+}
+
+type summaryGroup struct {
+	region  string
+	service string
+}
+
+type jsonSummary struct {
+	Total     int                   `json:"total"`
+	Providers []jsonProviderSummary `json:"providers"`
+	Unknown   int                   `json:"unknown"`
+	Errors    int                   `json:"errors"`
+}
+
+type jsonProviderSummary struct {
+	Provider string             `json:"provider"`
+	Count    int                `json:"count"`
+	Percent  float64            `json:"percent"`
+	Groups   []jsonSummaryGroup `json:"groups"`
+}
+
+type jsonSummaryGroup struct {
+	Region  string  `json:"region"`
+	Service string  `json:"service"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+func newSummary() *summary {
+	return &summary{providers: make(map[common.CloudProvider]*providerSummary)}
+}
+
+func (s *summary) Add(r common.Result) {
+	if r.Host != "" && r.Error == nil {
+		for _, address := range r.Addresses {
+			s.Add(address)
+		}
+		return
+	}
+
+	if r.Embedded != nil && r.Error == nil && r.Provider == "" {
+		s.Add(*r.Embedded)
+		return
+	}
+
+	s.total++
+	switch {
+	case r.Error != nil:
+		s.errors++
+	case r.Provider == "":
+		s.unknown++
+	default:
+		p, exists := s.providers[r.Provider]
+		if !exists {
+			p = &providerSummary{groups: make(map[summaryGroup]int)}
+			s.providers[r.Provider] = p
+		}
+		p.count++
+		p.groups[summaryGroup{region: r.Range.Region, service: r.Range.Service}]++
+	}
+}
+
+func (s *summary) percent(count int) float64 {
+	if s.total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(s.total)
+}
+
+// sortedProviders returns the providers by descending count, then by name.
+func (s *summary) sortedProviders() []common.CloudProvider {
+	providers := make([]common.CloudProvider, 0, len(s.providers))
+	for providerType := range s.providers {
+		providers = append(providers, providerType)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		a, b := s.providers[providers[i]].count, s.providers[providers[j]].count
+		if a != b {
+			return a > b
+		}
+		return providers[i] < providers[j]
+	})
+	return providers
+}
+
+// sortedGroups returns the region and service groups by descending count.
+func (p *providerSummary) sortedGroups() []summaryGroup {
+	groups := make([]summaryGroup, 0, len(p.groups))
+	for group := range p.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := p.groups[groups[i]], p.groups[groups[j]]
+		if a != b {
+			return a > b
+		}
+		if groups[i].region != groups[j].region {
+			return groups[i].region < groups[j].region
+		}
+		return groups[i].service < groups[j].service
+	})
+	return groups
+}
+
+func (s *summary) row(provider, region, service string, count int) []string {
+	return []string{provider, region, service, strconv.Itoa(count), fmt.Sprintf("%.1f%%", s.percent(count))}
+}
+
+// rows returns a total row per provider followed by its region and service
+// breakdown, then the unknown and error totals.
+func (s *summary) rows() [][]string {
+	var rows [][]string
+	for _, providerType := range s.sortedProviders() {
+		p := s.providers[providerType]
+		rows = append(rows, s.row(string(providerType), summaryAll, summaryAll, p.count))
+		for _, group := range p.sortedGroups() {
+			rows = append(rows, s.row(string(providerType), getFieldString(group.region), getFieldString(group.service), p.groups[group]))
+		}
+	}
+	if s.unknown > 0 {
+		rows = append(rows, s.row("unknown", summaryAll, summaryAll, s.unknown))
+	}
+	if s.errors > 0 {
+		rows = append(rows, s.row("ERROR", summaryAll, summaryAll, s.errors))
+	}
+	return rows
+}
+
+func (s *summary) json() jsonSummary {
+	result := jsonSummary{
+		Total:     s.total,
+		Providers: make([]jsonProviderSummary, 0, len(s.providers)),
+		Unknown:   s.unknown,
+		Errors:    s.errors,
+	}
+	for _, providerType := range s.sortedProviders() {
+		p := s.providers[providerType]
+		providerResult := jsonProviderSummary{
+			Provider: string(providerType),
+			Count:    p.count,
+			Percent:  s.percent(p.count),
+			Groups:   make([]jsonSummaryGroup, 0, len(p.groups)),
+		}
+		for _, group := range p.sortedGroups() {
+			providerResult.Groups = append(providerResult.Groups, jsonSummaryGroup{
+				Region:  group.region,
+				Service: group.service,
+				Count:   p.groups[group],
+				Percent: s.percent(p.groups[group]),
+			})
+		}
+		result.Providers = append(result.Providers, providerResult)
+	}
+	return result
+}
+
+func printSummary(w io.Writer, s *summary, flags *common.CloudIpFlag) error {
+	switch flags.Format {
+	case "text":
+		if flags.Header {
+			if _, err := fmt.Fprintln(w, strings.Join(summaryHeaderRow, flags.Delimiter)); err != nil {
+				return fmt.Errorf("error writing text summary: %w", err)
+			}
+		}
+		for _, row := range s.rows() {
+			if _, err := fmt.Fprintln(w, strings.Join(row, flags.Delimiter)); err != nil {
+				return fmt.Errorf("error writing text summary: %w", err)
+			}
+		}
+		return nil
+	case "table":
+		table := newTable(w, flags.Delimiter)
+		table.Header(summaryHeaderRow)
+		for _, row := range s.rows() {
+			table.Append(row)
+		}
+		if err := table.Render(); err != nil {
+			return fmt.Errorf("error writing table summary: %w", err)
+		}
+		return nil
+	case "json", "ndjson":
+		bytes, err := json.Marshal(s.json())
+		if err != nil {
+			return fmt.Errorf("error converting summary to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(bytes)); err != nil {
+			return fmt.Errorf("error writing JSON summary: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s. Supported formats are: text, table, json, ndjson", flags.Format)
+	}
+}
+
+// summaryResultWriter counts streamed results and prints the summary on Close.
+type summaryResultWriter struct {
+	w       io.Writer
+	flags   *common.CloudIpFlag
+	summary *summary
+}
+
+func (writer *summaryResultWriter) Write(result common.Result) error {
+	writer.summary.Add(result)
+	return nil
+}
+
+func (writer *summaryResultWriter) Close() error {
+	return printSummary(writer.w, writer.summary, writer.flags)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"cloudip/common"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func summaryTestResults() []common.Result {
+	ec2 := common.RangeInfo{Prefix: "3.0.0.0/15", Region: "us-east-1", Service: "EC2"}
+	return []common.Result{
+		{Ip: "3.0.0.1", Provider: common.AWS, Range: ec2},
+		{Ip: "3.0.0.2", Provider: common.AWS, Range: ec2},
+		{Ip: "3.0.0.3", Provider: common.AWS, Range: ec2},
+		{Ip: "34.0.0.1", Provider: common.GCP, Range: common.RangeInfo{Region: "us-central1"}},
+		{
+			Ip:   "api.example.com",
+			Host: "api.example.com",
+			Addresses: []common.Result{
+				{Ip: "3.0.0.4", Provider: common.AWS, Range: common.RangeInfo{Region: "eu-west-1", Service: "EC2"}},
+				{Ip: "192.0.2.1"},
+			},
+		},
+		{Ip: "192.0.2.2"},
+		{Ip: "192.0.2.3"},
+		{Ip: "bad-ip", Error: fmt.Errorf("error parsing IP: bad-ip")},
+		{Ip: "missing.example.com", Host: "missing.example.com", Error: fmt.Errorf("no such host")},
+	}
+}
+
+func TestPrintSummaryAsText(t *testing.T) {
+	s := newSummary()
+	for _, result := range summaryTestResults() {
+		s.Add(result)
+	}
+
+	output := new(bytes.Buffer)
+	flags := &common.CloudIpFlag{Format: "text", Delimiter: ",", Header: true}
+	if err := printSummary(output, s, flags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Provider,Region,Service,Count,Percent\n" +
+		"aws,*,*,4,40.0%\n" +
+		"aws,us-east-1,EC2,3,30.0%\n" +
+		"aws,eu-west-1,EC2,1,10.0%\n" +
+		"gcp,*,*,1,10.0%\n" +
+		"gcp,us-central1,-,1,10.0%\n" +
+		"unknown,*,*,3,30.0%\n" +
+		"ERROR,*,*,2,20.0%\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestSummaryCountsEmbeddedIPv4OfUnknownAddresses(t *testing.T) {
+	embedded := &common.Result{Ip: "3.0.0.1", Provider: common.AWS, Range: common.RangeInfo{Region: "us-east-1", Service: "EC2"}}
+	s := newSummary()
+	s.Add(common.Result{Ip: "64:ff9b::300:1", Embedded: embedded, Embedding: "nat64"})
+	s.Add(common.Result{Ip: "2002:300:1::1", Provider: common.GCP, Embedded: embedded, Embedding: "6to4"})
+	s.Add(common.Result{Ip: "64:ff9b::c000:201", Embedded: &common.Result{Ip: "192.0.2.1"}, Embedding: "nat64"})
+
+	if s.total != 3 || s.unknown != 1 || s.providers[common.AWS].count != 1 || s.providers[common.GCP].count != 1 {
+		t.Errorf("unexpected summary: total=%d unknown=%d providers=%+v", s.total, s.unknown, s.providers)
+	}
+}
+
+func TestPrintSummaryAsJson(t *testing.T) {
+	s := newSummary()
+	for _, result := range summaryTestResults() {
+		s.Add(result)
+	}
+
+	output := new(bytes.Buffer)
+	if err := printSummary(output, s, &common.CloudIpFlag{Format: "json"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed jsonSummary
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output.String())), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v, output: %q", err, output.String())
+	}
+	if parsed.Total != 10 || parsed.Unknown != 3 || parsed.Errors != 2 || len(parsed.Providers) != 2 {
+		t.Fatalf("unexpected summary: %+v", parsed)
+	}
+	aws := parsed.Providers[0]
+	if aws.Provider != "aws" || aws.Count != 4 || aws.Percent != 40 || len(aws.Groups) != 2 {
+		t.Errorf("unexpected AWS summary: %+v", aws)
+	}
+	if aws.Groups[0].Region != "us-east-1" || aws.Groups[0].Service != "EC2" || aws.Groups[0].Count != 3 {
+		t.Errorf("unexpected AWS group: %+v", aws.Groups[0])
+	}
+}
+
+func TestPrintSummaryAsTable(t *testing.T) {
+	s := newSummary()
+	s.Add(common.Result{Ip: "3.0.0.1", Provider: common.AWS, Range: common.RangeInfo{Region: "us-east-1", Service: "EC2"}})
+
+	output := new(bytes.Buffer)
+	if err := printSummary(output, s, &common.CloudIpFlag{Format: "table", Delimiter: " "}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(output.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "Provider") || !strings.Contains(lines[2], "us-east-1") {
+		t.Errorf("unexpected table summary:\n%s", output.String())
+	}
+}
+
+func TestRootCmdPrintsSummary(t *testing.T) {
+	cmd, _ := newTestCmd(t)
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetIn(strings.NewReader("8.8.8.8\n1.1.1.1\n"))
+	cmd.SetArgs([]string{"--summary", "9.9.9.9", "-"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.String() != "unknown * * 3 100.0%\n" {
+		t.Errorf("unexpected summary output: %q", stdout.String())
+	}
+
+	cmd, _ = newTestCmd(t)
+	stdout = new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"--summary", "--format", "json", "9.9.9.9", "bad-ip"})
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected command error for invalid IP")
+	}
+	if !strings.Contains(stdout.String(), `"total":2`) || !strings.Contains(stdout.String(), `"errors":1`) {
+		t.Errorf("unexpected JSON summary: %q", stdout.String())
+	}
+}
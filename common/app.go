@@ -1,19 +1,60 @@
 package common
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const AppName = "cloudip"
 
 const DefaultUpdateCheckTTL = 24 * time.Hour
 
 type CloudIpFlag struct {
+	All       bool
+	AsOf      string
 	Delimiter string
 	Format    string
 	Header    bool
+	Input     string
 	NoUpdate  bool
+	Resolver  string
+	Strategy  string
+	Summary   bool
 	Verbose   bool
 }
 
+// MatchStrategy decides which provider wins when several providers match an address.
+type MatchStrategy string
+
+const (
+	// StrategyOrdered picks the first matching provider in provider order.
+	StrategyOrdered MatchStrategy = "ordered"
+	// StrategyLongestPrefix picks the provider with the most specific matching prefix.
+	StrategyLongestPrefix MatchStrategy = "longest-prefix"
+)
+
+func ParseMatchStrategy(value string) (MatchStrategy, error) {
+	switch strategy := MatchStrategy(value); strategy {
+	case StrategyOrdered, StrategyLongestPrefix:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid match strategy: %s. Supported strategies are: %s, %s", value, StrategyOrdered, StrategyLongestPrefix)
+	}
+}
+
+// ParseAsOf parses the time of a historical lookup. A date without a time
+// means the end of that day in UTC, so data fetched during the day counts.
+func ParseAsOf(value string) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date.Add(24*time.Hour - time.Second), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s. Use YYYY-MM-DD or RFC 3339 (e.g. 2026-03-01T12:00:00Z)", value)
+	}
+	return at, nil
+}
+
 type UpdatePolicy struct {
 	NoUpdate bool
 	TTL      time.Duration
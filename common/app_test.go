@@ -0,0 +1,46 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMatchStrategy(t *testing.T) {
+	for _, value := range []string{"ordered", "longest-prefix"} {
+		strategy, err := ParseMatchStrategy(value)
+		if err != nil {
+			t.Fatalf("ParseMatchStrategy(%q) error = %v", value, err)
+		}
+		if string(strategy) != value {
+			t.Fatalf("ParseMatchStrategy(%q) = %q", value, strategy)
+		}
+	}
+
+	if _, err := ParseMatchStrategy("first"); err == nil {
+		t.Fatal("ParseMatchStrategy(first) error = nil, want error")
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"2026-03-01", time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC)},
+		{"2026-03-01T12:30:00Z", time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"2026-03-01T12:30:00+09:00", time.Date(2026, 3, 1, 3, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		at, err := ParseAsOf(tt.value)
+		if err != nil {
+			t.Fatalf("ParseAsOf(%q) error = %v", tt.value, err)
+		}
+		if !at.Equal(tt.expected) {
+			t.Errorf("ParseAsOf(%q) = %s, want %s", tt.value, at, tt.expected)
+		}
+	}
+
+	if _, err := ParseAsOf("03/01/2026"); err == nil {
+		t.Error("ParseAsOf(03/01/2026) error = nil, want error")
+	}
+}
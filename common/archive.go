@@ -0,0 +1,190 @@
+package common
+
+import (
+	"cloudip/util"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const archiveIndexFile = "index.json"
+
+// DataArchive keeps every distinct version of a provider's data, keyed by
+// the signature stored in CloudMetadata, so earlier versions can be compared
+// and queried after the data file is replaced.
+type DataArchive struct {
+	Provider CloudProvider
+	Dir      string
+}
+
+// ArchivedVersion describes one archived version of a provider's data.
+type ArchivedVersion struct {
+	Signature string    `json:"signature"`
+	FetchedAt time.Time `json:"fetchedAt"` // When the version was downloaded
+	File      string    `json:"file"`
+	Count     int       `json:"count"`
+}
+
+// Snapshot is an archived version with its ranges.
+type Snapshot struct {
+	ArchivedVersion
+	Provider CloudProvider
+	Ranges   []RangeInfo
+}
+
+type archivedRange struct {
+	Prefix     string            `json:"prefix"`
+	Region     string            `json:"region,omitempty"`
+	Service    string            `json:"service,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Versions returns the archived versions, oldest first.
+func (a *DataArchive) Versions() ([]ArchivedVersion, error) {
+	path := filepath.Join(a.Dir, archiveIndexFile)
+	if !util.IsFileExists(path) {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error opening archive index")
+	}
+	defer file.Close()
+
+	var versions []ArchivedVersion
+	if err := util.ReadJSON(file, &versions); err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading archive index")
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].FetchedAt.Before(versions[j].FetchedAt)
+	})
+	return versions, nil
+}
+
+// Version returns the archived version with the signature.
+func (a *DataArchive) Version(signature string) (ArchivedVersion, bool, error) {
+	versions, err := a.Versions()
+	if err != nil {
+		return ArchivedVersion{}, false, err
+	}
+	for _, version := range versions {
+		if version.Signature == signature {
+			return version, true, nil
+		}
+	}
+	return ArchivedVersion{}, false, nil
+}
+
+// VersionAt returns the version that was current at the given time: the
+// latest version fetched at or before it.
+func (a *DataArchive) VersionAt(at time.Time) (ArchivedVersion, bool, error) {
+	versions, err := a.Versions()
+	if err != nil {
+		return ArchivedVersion{}, false, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].FetchedAt.After(at) {
+			return versions[i], true, nil
+		}
+	}
+	return ArchivedVersion{}, false, nil
+}
+
+// Load returns the snapshot of an archived version.
+func (a *DataArchive) Load(version ArchivedVersion) (*Snapshot, error) {
+	file, err := os.Open(filepath.Join(a.Dir, version.File))
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error opening archived data")
+	}
+	defer file.Close()
+
+	var archived []archivedRange
+	if err := util.ReadJSON(file, &archived); err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading archived data")
+	}
+
+	snapshot := &Snapshot{ArchivedVersion: version, Provider: a.Provider, Ranges: make([]RangeInfo, 0, len(archived))}
+	for _, info := range archived {
+		snapshot.Ranges = append(snapshot.Ranges, RangeInfo(info))
+	}
+	return snapshot, nil
+}
+
+// Save archives the ranges of a version. A version that is archived already
+// is kept as it is, so FetchedAt records when it was first seen.
+func (a *DataArchive) Save(signature string, fetchedAt time.Time, ranges []RangeInfo) error {
+	if signature == "" {
+		return errors.New("cannot archive data without a signature")
+	}
+	versions, err := a.Versions()
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if version.Signature == signature {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(a.Dir, 0755); err != nil {
+		return util.ErrorWithInfo(err, "error creating archive directory")
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(signature))
+	version := ArchivedVersion{
+		Signature: signature,
+		FetchedAt: fetchedAt.UTC().Truncate(time.Second),
+		File:      fmt.Sprintf("%d-%08x.json", fetchedAt.Unix(), hash.Sum32()),
+		Count:     len(ranges),
+	}
+	archived := make([]archivedRange, 0, len(ranges))
+	for _, info := range ranges {
+		archived = append(archived, archivedRange(info))
+	}
+	if err := writeJSONFile(filepath.Join(a.Dir, version.File), &archived); err != nil {
+		return util.ErrorWithInfo(err, "error writing archived data")
+	}
+
+	versions = append(versions, version)
+	if err := writeJSONFile(filepath.Join(a.Dir, archiveIndexFile), &versions); err != nil {
+		return util.ErrorWithInfo(err, "error writing archive index")
+	}
+	return nil
+}
+
+// Keep archives the version loaded by load unless it is archived already.
+// Archiving is best effort: errors are reported but never fail an update. A
+// nil archive keeps nothing.
+func (a *DataArchive) Keep(signature string, fetchedAt time.Time, load func() ([]RangeInfo, error)) {
+	if a == nil || signature == "" {
+		return
+	}
+	if _, exists, err := a.Version(signature); err == nil && exists {
+		return
+	}
+
+	ranges, err := load()
+	if err == nil {
+		err = a.Save(signature, fetchedAt, ranges)
+	}
+	if err != nil {
+		util.PrintErrorTrace(util.ErrorWithInfo(err, fmt.Sprintf("error archiving %s data", a.Provider)))
+		return
+	}
+	VerboseOutput(fmt.Sprintf("Archived %s IP ranges [%s]", a.Provider, signature))
+}
+
+func writeJSONFile[T any](path string, data *T) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return util.WriteJSON(file, data)
+}
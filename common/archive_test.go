@@ -0,0 +1,98 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDataArchiveSavesDistinctVersions(t *testing.T) {
+	archive := &DataArchive{Provider: AWS, Dir: t.TempDir()}
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	if err := archive.Save("v2", second, []RangeInfo{{Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"}}); err != nil {
+		t.Fatalf("Save(v2) error = %v", err)
+	}
+	if err := archive.Save("v1", first, []RangeInfo{{Prefix: "54.230.0.0/16", Attributes: map[string]string{"network_border_group": "GLOBAL"}}}); err != nil {
+		t.Fatalf("Save(v1) error = %v", err)
+	}
+	if err := archive.Save("v2", second.Add(time.Hour), nil); err != nil {
+		t.Fatalf("Save(v2) again error = %v", err)
+	}
+
+	versions, err := archive.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].Signature != "v1" || versions[1].Signature != "v2" {
+		t.Fatalf("Versions() = %+v, want v1 and v2 oldest first", versions)
+	}
+	if !versions[1].FetchedAt.Equal(second) || versions[1].Count != 1 {
+		t.Errorf("Versions()[1] = %+v, want the first save of v2 kept", versions[1])
+	}
+
+	snapshot, err := archive.Load(versions[0])
+	if err != nil {
+		t.Fatalf("Load(v1) error = %v", err)
+	}
+	if snapshot.Provider != AWS || len(snapshot.Ranges) != 1 || snapshot.Ranges[0].Attributes["network_border_group"] != "GLOBAL" {
+		t.Errorf("Load(v1) = %+v, want the saved range with its attributes", snapshot)
+	}
+
+	if err := archive.Save("", first, nil); err == nil {
+		t.Error("Save() without a signature should fail")
+	}
+}
+
+func TestDataArchiveKeepLoadsOnlyNewVersions(t *testing.T) {
+	archive := &DataArchive{Provider: Cloudflare, Dir: t.TempDir()}
+	loads := 0
+	load := func() ([]RangeInfo, error) {
+		loads++
+		return []RangeInfo{{Prefix: "173.245.48.0/20"}}, nil
+	}
+
+	archive.Keep("etag", time.Now(), load)
+	archive.Keep("etag", time.Now(), load)
+	archive.Keep("", time.Now(), load)
+	archive.Keep("broken", time.Now(), func() ([]RangeInfo, error) {
+		return nil, errors.New("cannot read data file")
+	})
+
+	if loads != 1 {
+		t.Errorf("Keep() loaded the data %d times, want 1", loads)
+	}
+	if versions, err := archive.Versions(); err != nil || len(versions) != 1 {
+		t.Errorf("Versions() = %+v, %v, want only the etag version", versions, err)
+	}
+}
+
+func TestDataArchiveVersionAt(t *testing.T) {
+	archive := &DataArchive{Provider: GCP, Dir: t.TempDir()}
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, signature := range []string{"v1", "v2"} {
+		if err := archive.Save(signature, first.Add(time.Duration(i)*48*time.Hour), nil); err != nil {
+			t.Fatalf("Save(%s) error = %v", signature, err)
+		}
+	}
+
+	tests := []struct {
+		at        time.Time
+		signature string
+	}{
+		{first.Add(-time.Second), ""},
+		{first, "v1"},
+		{first.Add(47 * time.Hour), "v1"},
+		{first.Add(72 * time.Hour), "v2"},
+	}
+	for _, tt := range tests {
+		version, found, err := archive.VersionAt(tt.at)
+		if err != nil {
+			t.Fatalf("VersionAt(%s) error = %v", tt.at, err)
+		}
+		if found != (tt.signature != "") || version.Signature != tt.signature {
+			t.Errorf("VersionAt(%s) = %q, %v, want %q", tt.at, version.Signature, found, tt.signature)
+		}
+	}
+}
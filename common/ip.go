@@ -1,20 +1,99 @@
 package common
 
+import "maps"
+
 type Result struct {
-	Ip       string
+	Ip        string
+	Provider  CloudProvider
+	Range     RangeInfo
+	Matches   []Match
+	Strategy  MatchStrategy
+	Coverage  Coverage
+	Host      string   // Set for hostname inputs
+	Addresses []Result // Results of the addresses a hostname resolved to
+	Embedded  *Result  // Result of the IPv4 address embedded in an IPv6 transition address
+	Embedding string   // Format Embedded was extracted from, such as "nat64"
+	Error     error
+}
+
+// Match is a provider range that contains the checked address. For prefix
+// and range inputs it is a provider range overlapping the checked block.
+type Match struct {
 	Provider CloudProvider
-	Error    error
+	Range    RangeInfo
+	Coverage Coverage
+}
+
+// Coverage describes how much of a checked prefix or address range is
+// covered by provider ranges.
+type Coverage string
+
+const (
+	CoverageFull    Coverage = "full"
+	CoveragePartial Coverage = "partial"
+	CoverageNone    Coverage = "none"
+)
+
+// RangeInfo describes a published provider prefix and its attributes.
+type RangeInfo struct {
+	Prefix     string
+	Region     string
+	Service    string
+	Attributes map[string]string
+}
+
+// Merge fills the empty fields of info with the values from other. The
+// attributes are copied before they are added to, since providers may share
+// one attributes map between the ranges of a group.
+func (info *RangeInfo) Merge(other RangeInfo) {
+	if info.Prefix == "" {
+		info.Prefix = other.Prefix
+	}
+	if info.Region == "" {
+		info.Region = other.Region
+	}
+	if info.Service == "" {
+		info.Service = other.Service
+	}
+	copied := false
+	for key, value := range other.Attributes {
+		if value == "" {
+			continue
+		}
+		if _, exists := info.Attributes[key]; exists {
+			continue
+		}
+		if !copied {
+			info.Attributes = maps.Clone(info.Attributes)
+			if info.Attributes == nil {
+				info.Attributes = make(map[string]string)
+			}
+			copied = true
+		}
+		info.Attributes[key] = value
+	}
 }
 
 const (
 	AWS        CloudProvider = "aws"
 	GCP        CloudProvider = "gcp"
+	Google     CloudProvider = "google" // Google services, outside the GCP customer ranges
 	Azure      CloudProvider = "azure"
 	Cloudflare CloudProvider = "cloudflare"
+	OCI        CloudProvider = "oci"
+
+	// Hosting providers that only publish RFC 8805 geofeeds.
+	DigitalOcean CloudProvider = "digitalocean"
+	Linode       CloudProvider = "linode"
+	Vultr        CloudProvider = "vultr"
 )
 
 type CloudProvider string
 
+// Reserved is reported instead of a provider for special-purpose addresses
+// that are not globally reachable, such as private or loopback addresses.
+const Reserved CloudProvider = "reserved"
+
 type CloudMetadata struct {
 	Type        CloudProvider `json:"type"`
 	Signature   string        `json:"signature"`
@@ -0,0 +1,215 @@
+package export
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Family is the IP version of a RangeSet.
+type Family int
+
+const (
+	// AllFamilies exports IPv4 and IPv6 sets.
+	AllFamilies Family = 0
+	IPv4        Family = 4
+	IPv6        Family = 6
+)
+
+// RangeSet is the aggregated list of prefixes of one provider, optionally
+// narrowed to one service and region, for one IP family.
+type RangeSet struct {
+	Provider  common.CloudProvider
+	Service   string
+	Region    string
+	Signature string
+	Family    Family
+	Prefixes  []netip.Prefix
+}
+
+// Options tune the rendered artifacts.
+type Options struct {
+	Family     Family // Only export sets of this family
+	Target     string // iptables jump target, ACCEPT when empty
+	MaxEntries int    // Widen prefixes until every set fits, no limit when 0
+}
+
+// summarizedFormats are the formats whose targets limit the number of
+// entries. Summarizing widens the prefixes, so the other formats, which are
+// mostly allowlists, never trust addresses the provider did not publish.
+var summarizedFormats = map[string]bool{
+	"aws-prefix-list": true,
+	"terraform":       true,
+	"tfvars-json":     true,
+}
+
+type renderFunc func(w io.Writer, sets []RangeSet, options Options) error
+
+var renderers = map[string]renderFunc{
+	"apache":                  renderApache,
+	"aws-prefix-list":         renderAWSPrefixList,
+	"calico-globalnetworkset": renderCalicoGlobalNetworkSet,
+	"cilium-cidrgroup":        renderCiliumCIDRGroup,
+	"haproxy-acl":             renderHaproxyACL,
+	"haproxy-map":             renderHaproxyMap,
+	"ipset":                   renderIpset,
+	"iptables":                renderIptables,
+	"k8s-networkpolicy":       renderNetworkPolicy,
+	"nftables":                renderNftables,
+	"nginx-geo":               renderNginxGeo,
+	"nginx-realip":            renderNginxRealIP,
+	"terraform":               renderTerraform,
+	"tfvars-json":             renderTfvarsJSON,
+}
+
+// Formats returns the supported export formats in alphabetical order.
+func Formats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewRangeSets aggregates the prefixes and splits them into an IPv4 and an
+// IPv6 set that share the provider, service, region and signature of the
+// template. Families without prefixes are omitted.
+func NewRangeSets(template RangeSet, prefixes []netip.Prefix) []RangeSet {
+	var sets []RangeSet
+	for _, family := range []Family{IPv4, IPv6} {
+		var familyPrefixes []netip.Prefix
+		for _, prefix := range prefixes {
+			if prefix.Addr().Is4() == (family == IPv4) {
+				familyPrefixes = append(familyPrefixes, prefix)
+			}
+		}
+		if len(familyPrefixes) == 0 {
+			continue
+		}
+
+		set := template
+		set.Family = family
+		set.Prefixes = util.AggregatePrefixes(familyPrefixes)
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// Render writes the sets in the given format. Sets with more than
+// options.MaxEntries prefixes are summarized first, which only the formats
+// with an entry limit allow. The output only depends on the sets, so the same
+// provider data always renders the same artifact.
+func Render(w io.Writer, format string, sets []RangeSet, options Options) error {
+	render, exists := renderers[format]
+	if !exists {
+		return fmt.Errorf("invalid export format: %s. Supported formats are: %s", format, strings.Join(Formats(), ", "))
+	}
+	if options.MaxEntries > 0 && !summarizedFormats[format] {
+		return fmt.Errorf("max entries is not supported by %s export: widening prefixes would allow addresses the provider does not publish. Supported formats are: aws-prefix-list, terraform, tfvars-json", format)
+	}
+
+	selected := make([]RangeSet, 0, len(sets))
+	for _, set := range sets {
+		if options.Family != AllFamilies && set.Family != options.Family {
+			continue
+		}
+		if options.MaxEntries > 0 {
+			set.Prefixes = util.SummarizePrefixes(set.Prefixes, options.MaxEntries)
+		}
+		selected = append(selected, set)
+	}
+	return render(w, selected, options)
+}
+
+// Name returns a set name such as "cloudip-aws-cloudfront-v4". Characters
+// other than letters and digits are replaced by the separator, and names
+// longer than maxLength are shortened with a hash of the full name so they
+// stay unique.
+func (set RangeSet) Name(separator string, maxLength int) string {
+	return set.name(separator, maxLength, fmt.Sprintf("v%d", set.Family))
+}
+
+// SelectionName returns the name shared by the IPv4 and IPv6 sets of a
+// selection, such as "cloudip-aws-ec2-us-east-1".
+func (set RangeSet) SelectionName(separator string, maxLength int) string {
+	return set.name(separator, maxLength)
+}
+
+func (set RangeSet) name(separator string, maxLength int, suffixes ...string) string {
+	parts := []string{common.AppName, string(set.Provider)}
+	for _, part := range []string{set.Service, set.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	parts = append(parts, suffixes...)
+
+	var builder strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			builder.WriteString(separator)
+		}
+		for _, char := range strings.ToLower(part) {
+			if char >= 'a' && char <= 'z' || char >= '0' && char <= '9' {
+				builder.WriteRune(char)
+			} else {
+				builder.WriteString(separator)
+			}
+		}
+	}
+
+	name := builder.String()
+	if maxLength <= 0 || len(name) <= maxLength {
+		return name
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	suffix := fmt.Sprintf("%s%08x", separator, hash.Sum32())
+	return name[:maxLength-len(suffix)] + suffix
+}
+
+// writeHeader writes a comment naming the data every set was generated from.
+func writeHeader(w io.Writer, comment string, sets []RangeSet) error {
+	lines := []string{"Generated by " + common.AppName + " export. Do not edit."}
+	seen := make(map[string]bool)
+	for _, set := range sets {
+		source := string(set.Provider)
+		for _, part := range []string{set.Service, set.Region} {
+			if part != "" {
+				source += " " + part
+			}
+		}
+		if seen[source] {
+			continue
+		}
+		seen[source] = true
+		if set.Signature != "" {
+			source += " (signature " + set.Signature + ")"
+		}
+		lines = append(lines, "Source: "+source)
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(w, "%s %s\n", comment, line); err != nil {
+			return fmt.Errorf("error writing export header: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeExport writes the header comment followed by the rendered body.
+func writeExport(w io.Writer, format string, sets []RangeSet, body string) error {
+	if err := writeHeader(w, "#", sets); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, body); err != nil {
+		return fmt.Errorf("error writing %s export: %w", format, err)
+	}
+	return nil
+}
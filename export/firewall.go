@@ -0,0 +1,94 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// ipsetMaxNameLength is the longest set name ipset accepts.
+	ipsetMaxNameLength = 31
+	// iptablesMaxChainLength is the longest chain name iptables accepts.
+	iptablesMaxChainLength = 28
+	// ipsetDefaultMaxElem is the default maxelem of ipset hash sets.
+	ipsetDefaultMaxElem = 65536
+)
+
+// renderNftables writes an nftables script with one named interval set per
+// provider and family, loadable with `nft -f`.
+func renderNftables(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	builder.WriteString("table inet cloudip {\n")
+	for _, set := range sets {
+		addrType := "ipv4_addr"
+		if set.Family == IPv6 {
+			addrType = "ipv6_addr"
+		}
+		fmt.Fprintf(&builder, "\tset %s {\n", set.Name("_", 0))
+		fmt.Fprintf(&builder, "\t\ttype %s\n", addrType)
+		builder.WriteString("\t\tflags interval\n")
+		builder.WriteString("\t\telements = {\n")
+		for i, prefix := range set.Prefixes {
+			separator := ","
+			if i == len(set.Prefixes)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(&builder, "\t\t\t%s%s\n", prefix, separator)
+		}
+		builder.WriteString("\t\t}\n")
+		builder.WriteString("\t}\n")
+	}
+	builder.WriteString("}\n")
+
+	return writeExport(w, "nftables", sets, builder.String())
+}
+
+// renderIpset writes an `ipset restore` file that creates or flushes one
+// hash:net set per provider and family and fills it.
+func renderIpset(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	for _, set := range sets {
+		name := set.Name("-", ipsetMaxNameLength)
+		family := "inet"
+		if set.Family == IPv6 {
+			family = "inet6"
+		}
+		fmt.Fprintf(&builder, "create %s hash:net family %s maxelem %d -exist\n", name, family, max(ipsetDefaultMaxElem, len(set.Prefixes)))
+		fmt.Fprintf(&builder, "flush %s\n", name)
+		for _, prefix := range set.Prefixes {
+			fmt.Fprintf(&builder, "add %s %s\n", name, prefix)
+		}
+	}
+
+	return writeExport(w, "ipset", sets, builder.String())
+}
+
+// renderIptables writes an iptables-restore file with one chain per provider
+// that jumps to the target for every prefix. iptables and ip6tables take
+// separate files, so a single family must be selected.
+func renderIptables(w io.Writer, sets []RangeSet, options Options) error {
+	if options.Family == AllFamilies {
+		return errors.New("iptables export needs a single IP family: use --ipv4 for iptables-restore or --ipv6 for ip6tables-restore")
+	}
+	target := options.Target
+	if target == "" {
+		target = "ACCEPT"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("*filter\n")
+	for _, set := range sets {
+		fmt.Fprintf(&builder, ":%s - [0:0]\n", strings.ToUpper(set.Name("-", iptablesMaxChainLength)))
+	}
+	for _, set := range sets {
+		chain := strings.ToUpper(set.Name("-", iptablesMaxChainLength))
+		for _, prefix := range set.Prefixes {
+			fmt.Fprintf(&builder, "-A %s -s %s -j %s\n", chain, prefix, target)
+		}
+	}
+	builder.WriteString("COMMIT\n")
+
+	return writeExport(w, "iptables", sets, builder.String())
+}
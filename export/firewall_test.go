@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"cloudip/common"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func testRangeSets() []RangeSet {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("173.245.49.0/24"),
+		netip.MustParsePrefix("173.245.48.0/24"),
+		netip.MustParsePrefix("103.21.244.0/22"),
+		netip.MustParsePrefix("2400:cb00::/32"),
+	}
+	return NewRangeSets(RangeSet{Provider: common.Cloudflare, Signature: "1760000000"}, prefixes)
+}
+
+func TestNewRangeSetsSplitsAndAggregates(t *testing.T) {
+	sets := testRangeSets()
+	if len(sets) != 2 {
+		t.Fatalf("expected IPv4 and IPv6 sets, got %+v", sets)
+	}
+	if sets[0].Family != IPv4 || len(sets[0].Prefixes) != 2 || sets[0].Prefixes[1].String() != "173.245.48.0/23" {
+		t.Errorf("unexpected IPv4 set: %+v", sets[0])
+	}
+	if sets[1].Family != IPv6 || len(sets[1].Prefixes) != 1 {
+		t.Errorf("unexpected IPv6 set: %+v", sets[1])
+	}
+
+	if sets := NewRangeSets(RangeSet{Provider: common.AWS}, []netip.Prefix{netip.MustParsePrefix("2600:9000::/28")}); len(sets) != 1 || sets[0].Family != IPv6 {
+		t.Errorf("expected only an IPv6 set, got %+v", sets)
+	}
+}
+
+func TestRangeSetName(t *testing.T) {
+	set := RangeSet{Provider: common.Azure, Service: "AzureFrontDoor.Backend", Family: IPv6}
+
+	if got := set.Name("_", 0); got != "cloudip_azure_azurefrontdoor_backend_v6" {
+		t.Errorf("unexpected unlimited name: %s", got)
+	}
+	short := set.Name("-", 31)
+	if len(short) != 31 || !strings.HasPrefix(short, "cloudip-azure-azure") {
+		t.Errorf("unexpected shortened name: %s", short)
+	}
+	other := RangeSet{Provider: common.Azure, Service: "AzureFrontDoor.Frontend", Family: IPv6}
+	if other.Name("-", 31) == short {
+		t.Errorf("expected shortened names to stay unique, both are %s", short)
+	}
+	if got := (RangeSet{Provider: common.Cloudflare, Family: IPv4}).Name("-", 31); got != "cloudip-cloudflare-v4" {
+		t.Errorf("unexpected name: %s", got)
+	}
+}
+
+func TestRenderNftables(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "nftables", testRangeSets(), Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# Generated by cloudip export. Do not edit.
+# Source: cloudflare (signature 1760000000)
+table inet cloudip {
+	set cloudip_cloudflare_v4 {
+		type ipv4_addr
+		flags interval
+		elements = {
+			103.21.244.0/22,
+			173.245.48.0/23
+		}
+	}
+	set cloudip_cloudflare_v6 {
+		type ipv6_addr
+		flags interval
+		elements = {
+			2400:cb00::/32
+		}
+	}
+}
+`
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestRenderIpset(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "ipset", testRangeSets(), Options{Family: IPv6}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# Generated by cloudip export. Do not edit.
+# Source: cloudflare (signature 1760000000)
+create cloudip-cloudflare-v6 hash:net family inet6 maxelem 65536 -exist
+flush cloudip-cloudflare-v6
+add cloudip-cloudflare-v6 2400:cb00::/32
+`
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestRenderIptables(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "iptables", testRangeSets(), Options{Family: IPv4, Target: "RETURN"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# Generated by cloudip export. Do not edit.
+# Source: cloudflare (signature 1760000000)
+*filter
+:CLOUDIP-CLOUDFLARE-V4 - [0:0]
+-A CLOUDIP-CLOUDFLARE-V4 -s 103.21.244.0/22 -j RETURN
+-A CLOUDIP-CLOUDFLARE-V4 -s 173.245.48.0/23 -j RETURN
+COMMIT
+`
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	if err := Render(new(bytes.Buffer), "iptables", testRangeSets(), Options{}); err == nil {
+		t.Error("expected error for iptables export without a single family")
+	}
+}
+
+func TestRenderRejectsUnknownFormat(t *testing.T) {
+	err := Render(new(bytes.Buffer), "pf", testRangeSets(), Options{})
+	if err == nil || !strings.Contains(err.Error(), "ipset, iptables, k8s-networkpolicy, nftables") {
+		t.Fatalf("expected error listing formats, got %v", err)
+	}
+}
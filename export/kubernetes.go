@@ -0,0 +1,133 @@
+package export
+
+import (
+	"cloudip/common"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxObjectNameLength keeps object names valid as DNS labels, which is the
+// strictest rule the supported resource kinds apply.
+const maxObjectNameLength = 63
+
+// selection is the IPv4 and IPv6 sets of one provider, service and region,
+// rendered as a single Kubernetes object.
+type selection struct {
+	RangeSet
+	cidrs []string
+}
+
+// groupSelections merges the family sets of each selection in set order.
+func groupSelections(sets []RangeSet) []selection {
+	var selections []selection
+	index := make(map[string]int)
+	for _, set := range sets {
+		name := set.SelectionName("-", maxObjectNameLength)
+		i, exists := index[name]
+		if !exists {
+			i = len(selections)
+			index[name] = i
+			selections = append(selections, selection{RangeSet: set})
+		}
+		for _, prefix := range set.Prefixes {
+			selections[i].cidrs = append(selections[i].cidrs, prefix.String())
+		}
+	}
+	return selections
+}
+
+// labelValue turns a provider, service or region into a valid label value.
+func labelValue(value string) string {
+	var builder strings.Builder
+	for _, char := range strings.ToLower(value) {
+		if char >= 'a' && char <= 'z' || char >= '0' && char <= '9' || char == '.' || char == '_' {
+			builder.WriteRune(char)
+		} else {
+			builder.WriteRune('-')
+		}
+	}
+	label := builder.String()
+	if len(label) > maxObjectNameLength {
+		label = label[:maxObjectNameLength]
+	}
+	return strings.Trim(label, "-._")
+}
+
+// writeMetadata writes the object metadata with labels selecting the source
+// of the ranges, indented for a top-level key.
+func writeMetadata(builder *strings.Builder, s selection) {
+	builder.WriteString("metadata:\n")
+	fmt.Fprintf(builder, "  name: %s\n", s.SelectionName("-", maxObjectNameLength))
+	builder.WriteString("  labels:\n")
+	fmt.Fprintf(builder, "    app.kubernetes.io/managed-by: %s\n", common.AppName)
+	fmt.Fprintf(builder, "    cloudip/provider: %s\n", labelValue(string(s.Provider)))
+	if s.Service != "" {
+		fmt.Fprintf(builder, "    cloudip/service: %s\n", labelValue(s.Service))
+	}
+	if s.Region != "" {
+		fmt.Fprintf(builder, "    cloudip/region: %s\n", labelValue(s.Region))
+	}
+}
+
+// renderManifests writes one YAML document per selection, separated by "---".
+func renderManifests(w io.Writer, format string, sets []RangeSet, object func(*strings.Builder, selection)) error {
+	var builder strings.Builder
+	for i, s := range groupSelections(sets) {
+		if i > 0 {
+			builder.WriteString("---\n")
+		}
+		object(&builder, s)
+	}
+	return writeExport(w, format, sets, builder.String())
+}
+
+// renderNetworkPolicy writes NetworkPolicy objects allowing egress from every
+// pod in the namespace to the provider ranges.
+func renderNetworkPolicy(w io.Writer, sets []RangeSet, _ Options) error {
+	return renderManifests(w, "k8s-networkpolicy", sets, func(builder *strings.Builder, s selection) {
+		builder.WriteString("apiVersion: networking.k8s.io/v1\n")
+		builder.WriteString("kind: NetworkPolicy\n")
+		writeMetadata(builder, s)
+		builder.WriteString("spec:\n")
+		builder.WriteString("  podSelector: {}\n")
+		builder.WriteString("  policyTypes:\n")
+		builder.WriteString("  - Egress\n")
+		builder.WriteString("  egress:\n")
+		builder.WriteString("  - to:\n")
+		for _, cidr := range s.cidrs {
+			builder.WriteString("    - ipBlock:\n")
+			fmt.Fprintf(builder, "        cidr: %q\n", cidr)
+		}
+	})
+}
+
+// renderCiliumCIDRGroup writes CiliumCIDRGroup objects that policies reference
+// through fromCIDRSet or toCIDRSet.
+func renderCiliumCIDRGroup(w io.Writer, sets []RangeSet, _ Options) error {
+	return renderManifests(w, "cilium-cidrgroup", sets, func(builder *strings.Builder, s selection) {
+		builder.WriteString("apiVersion: cilium.io/v2alpha1\n")
+		builder.WriteString("kind: CiliumCIDRGroup\n")
+		writeMetadata(builder, s)
+		builder.WriteString("spec:\n")
+		builder.WriteString("  externalCIDRs:\n")
+		for _, cidr := range s.cidrs {
+			fmt.Fprintf(builder, "  - %q\n", cidr)
+		}
+	})
+}
+
+// renderCalicoGlobalNetworkSet writes GlobalNetworkSet objects that policies
+// select by their cloudip labels.
+func renderCalicoGlobalNetworkSet(w io.Writer, sets []RangeSet, _ Options) error {
+	return renderManifests(w, "calico-globalnetworkset", sets, func(builder *strings.Builder, s selection) {
+		builder.WriteString("apiVersion: projectcalico.org/v3\n")
+		builder.WriteString("kind: GlobalNetworkSet\n")
+		writeMetadata(builder, s)
+		builder.WriteString("spec:\n")
+		builder.WriteString("  nets:\n")
+		for _, cidr := range s.cidrs {
+			fmt.Fprintf(builder, "  - %q\n", cidr)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"cloudip/common"
+	"net/netip"
+	"testing"
+)
+
+func testKubernetesSets() []RangeSet {
+	sets := testRangeSets()
+	return append(sets, NewRangeSets(RangeSet{Provider: common.AWS, Service: "S3", Region: "us-east-1"}, []netip.Prefix{
+		netip.MustParsePrefix("3.5.0.0/19"),
+	})...)
+}
+
+func TestRenderKubernetesFormats(t *testing.T) {
+	header := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: cloudflare (signature 1760000000)\n" +
+		"# Source: aws S3 us-east-1\n"
+	cloudflareMetadata := "metadata:\n" +
+		"  name: cloudip-cloudflare\n" +
+		"  labels:\n" +
+		"    app.kubernetes.io/managed-by: cloudip\n" +
+		"    cloudip/provider: cloudflare\n"
+	awsMetadata := "metadata:\n" +
+		"  name: cloudip-aws-s3-us-east-1\n" +
+		"  labels:\n" +
+		"    app.kubernetes.io/managed-by: cloudip\n" +
+		"    cloudip/provider: aws\n" +
+		"    cloudip/service: s3\n" +
+		"    cloudip/region: us-east-1\n"
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "k8s-networkpolicy",
+			expected: "apiVersion: networking.k8s.io/v1\n" +
+				"kind: NetworkPolicy\n" +
+				cloudflareMetadata +
+				"spec:\n" +
+				"  podSelector: {}\n" +
+				"  policyTypes:\n" +
+				"  - Egress\n" +
+				"  egress:\n" +
+				"  - to:\n" +
+				"    - ipBlock:\n" +
+				"        cidr: \"103.21.244.0/22\"\n" +
+				"    - ipBlock:\n" +
+				"        cidr: \"173.245.48.0/23\"\n" +
+				"    - ipBlock:\n" +
+				"        cidr: \"2400:cb00::/32\"\n" +
+				"---\n" +
+				"apiVersion: networking.k8s.io/v1\n" +
+				"kind: NetworkPolicy\n" +
+				awsMetadata +
+				"spec:\n" +
+				"  podSelector: {}\n" +
+				"  policyTypes:\n" +
+				"  - Egress\n" +
+				"  egress:\n" +
+				"  - to:\n" +
+				"    - ipBlock:\n" +
+				"        cidr: \"3.5.0.0/19\"\n",
+		},
+		{
+			format: "cilium-cidrgroup",
+			expected: "apiVersion: cilium.io/v2alpha1\n" +
+				"kind: CiliumCIDRGroup\n" +
+				cloudflareMetadata +
+				"spec:\n" +
+				"  externalCIDRs:\n" +
+				"  - \"103.21.244.0/22\"\n" +
+				"  - \"173.245.48.0/23\"\n" +
+				"  - \"2400:cb00::/32\"\n" +
+				"---\n" +
+				"apiVersion: cilium.io/v2alpha1\n" +
+				"kind: CiliumCIDRGroup\n" +
+				awsMetadata +
+				"spec:\n" +
+				"  externalCIDRs:\n" +
+				"  - \"3.5.0.0/19\"\n",
+		},
+		{
+			format: "calico-globalnetworkset",
+			expected: "apiVersion: projectcalico.org/v3\n" +
+				"kind: GlobalNetworkSet\n" +
+				cloudflareMetadata +
+				"spec:\n" +
+				"  nets:\n" +
+				"  - \"103.21.244.0/22\"\n" +
+				"  - \"173.245.48.0/23\"\n" +
+				"  - \"2400:cb00::/32\"\n" +
+				"---\n" +
+				"apiVersion: projectcalico.org/v3\n" +
+				"kind: GlobalNetworkSet\n" +
+				awsMetadata +
+				"spec:\n" +
+				"  nets:\n" +
+				"  - \"3.5.0.0/19\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output := new(bytes.Buffer)
+			if err := Render(output, tt.format, testKubernetesSets(), Options{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != header+tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", header+tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"AzureFrontDoor.Backend", "azurefrontdoor.backend"},
+		{"ROUTE53_HEALTHCHECKS", "route53_healthchecks"},
+		{"us-east-1", "us-east-1"},
+		{"(global)", "global"},
+	}
+
+	for _, tt := range tests {
+		if got := labelValue(tt.value); got != tt.expected {
+			t.Errorf("labelValue(%q) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+}
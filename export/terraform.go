@@ -0,0 +1,100 @@
+package export
+
+import (
+	"cloudip/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// awsPrefixListMaxDescriptionLength is the longest entry description a
+// managed prefix list accepts.
+const awsPrefixListMaxDescriptionLength = 255
+
+// renderTerraform writes a locals block with one list of CIDRs per provider
+// and family, for use in security group or firewall rules.
+func renderTerraform(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	builder.WriteString("locals {\n")
+	for i, set := range sets {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "  %s = [\n", set.Name("_", 0))
+		for _, prefix := range set.Prefixes {
+			fmt.Fprintf(&builder, "    %q,\n", prefix.String())
+		}
+		builder.WriteString("  ]\n")
+	}
+	builder.WriteString("}\n")
+
+	return writeExport(w, "terraform", sets, builder.String())
+}
+
+// renderTfvarsJSON writes a .tfvars.json file assigning one list of CIDRs per
+// provider and family. JSON has no comments, so there is no header.
+func renderTfvarsJSON(w io.Writer, sets []RangeSet, _ Options) error {
+	variables := make(map[string][]string, len(sets))
+	for _, set := range sets {
+		cidrs := make([]string, 0, len(set.Prefixes))
+		for _, prefix := range set.Prefixes {
+			cidrs = append(cidrs, prefix.String())
+		}
+		variables[set.Name("_", 0)] = cidrs
+	}
+	return writeJSON(w, "tfvars-json", variables)
+}
+
+// awsPrefixListEntry is an entry of `aws ec2 create-managed-prefix-list
+// --entries` and `modify-managed-prefix-list --add-entries`.
+type awsPrefixListEntry struct {
+	Cidr        string `json:"Cidr"`
+	Description string `json:"Description"`
+}
+
+// renderAWSPrefixList writes the entries of one managed prefix list covering
+// every selection. A prefix list holds a single address family, and its entry
+// count is fixed when it is created, so the sets are merged and, with
+// MaxEntries, summarized as a whole.
+func renderAWSPrefixList(w io.Writer, sets []RangeSet, options Options) error {
+	if options.Family == AllFamilies {
+		return errors.New("aws-prefix-list export needs a single IP family: a managed prefix list holds either --ipv4 or --ipv6 entries")
+	}
+
+	var prefixes []netip.Prefix
+	for _, set := range sets {
+		prefixes = append(prefixes, set.Prefixes...)
+	}
+
+	entries := make([]awsPrefixListEntry, 0, len(prefixes))
+	for _, prefix := range util.SummarizePrefixes(prefixes, options.MaxEntries) {
+		var labels []string
+		for _, set := range sets {
+			for _, member := range set.Prefixes {
+				if member.Overlaps(prefix) {
+					labels = append(labels, set.Label())
+					break
+				}
+			}
+		}
+		description := strings.Join(labels, " ")
+		if len(description) > awsPrefixListMaxDescriptionLength {
+			description = description[:awsPrefixListMaxDescriptionLength]
+		}
+		entries = append(entries, awsPrefixListEntry{Cidr: prefix.String(), Description: description})
+	}
+	return writeJSON(w, "aws-prefix-list", entries)
+}
+
+// writeJSON writes the value as indented JSON.
+func writeJSON(w io.Writer, format string, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("error writing %s export: %w", format, err)
+	}
+	return nil
+}
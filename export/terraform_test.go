@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderTerraform(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "terraform", testRangeSets(), Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: cloudflare (signature 1760000000)\n" +
+		"locals {\n" +
+		"  cloudip_cloudflare_v4 = [\n" +
+		"    \"103.21.244.0/22\",\n" +
+		"    \"173.245.48.0/23\",\n" +
+		"  ]\n" +
+		"\n" +
+		"  cloudip_cloudflare_v6 = [\n" +
+		"    \"2400:cb00::/32\",\n" +
+		"  ]\n" +
+		"}\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestRenderTfvarsJSON(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "tfvars-json", testRangeSets(), Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\n" +
+		"  \"cloudip_cloudflare_v4\": [\n" +
+		"    \"103.21.244.0/22\",\n" +
+		"    \"173.245.48.0/23\"\n" +
+		"  ],\n" +
+		"  \"cloudip_cloudflare_v6\": [\n" +
+		"    \"2400:cb00::/32\"\n" +
+		"  ]\n" +
+		"}\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestRenderAWSPrefixList(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "aws-prefix-list", testWebServerSets(), Options{Family: IPv4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "[\n" +
+		"  {\n" +
+		"    \"Cidr\": \"13.32.0.0/15\",\n" +
+		"    \"Description\": \"aws:CLOUDFRONT\"\n" +
+		"  },\n" +
+		"  {\n" +
+		"    \"Cidr\": \"103.21.244.0/22\",\n" +
+		"    \"Description\": \"cloudflare aws:CLOUDFRONT\"\n" +
+		"  },\n" +
+		"  {\n" +
+		"    \"Cidr\": \"173.245.48.0/23\",\n" +
+		"    \"Description\": \"cloudflare\"\n" +
+		"  }\n" +
+		"]\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	if err := Render(new(bytes.Buffer), "aws-prefix-list", testRangeSets(), Options{}); err == nil || !strings.Contains(err.Error(), "single IP family") {
+		t.Errorf("expected error for prefix list without a single family, got %v", err)
+	}
+}
+
+func TestRenderSummarizesToMaxEntries(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "aws-prefix-list", testWebServerSets(), Options{Family: IPv4, MaxEntries: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "[\n" +
+		"  {\n" +
+		"    \"Cidr\": \"0.0.0.0/1\",\n" +
+		"    \"Description\": \"cloudflare aws:CLOUDFRONT\"\n" +
+		"  },\n" +
+		"  {\n" +
+		"    \"Cidr\": \"173.245.48.0/23\",\n" +
+		"    \"Description\": \"cloudflare\"\n" +
+		"  }\n" +
+		"]\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	if err := Render(output, "tfvars-json", testRangeSets(), Options{MaxEntries: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output.String(), "\"0.0.0.0/0\"") || !strings.Contains(output.String(), "\"2400:cb00::/32\"") {
+		t.Errorf("expected every set summarized to one entry, got:\n%s", output.String())
+	}
+}
+
+func TestRenderRejectsMaxEntriesForAllowlists(t *testing.T) {
+	for _, format := range []string{"haproxy-acl", "nginx-realip", "iptables", "ipset", "apache"} {
+		err := Render(new(bytes.Buffer), format, testRangeSets(), Options{MaxEntries: 1})
+		if err == nil || !strings.Contains(err.Error(), "max entries is not supported") {
+			t.Errorf("%s: expected max entries error, got %v", format, err)
+		}
+	}
+}
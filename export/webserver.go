@@ -0,0 +1,97 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// Label returns the selection of the set in "provider[:service][@region]"
+// form, the value the map formats associate with each prefix.
+func (set RangeSet) Label() string {
+	label := string(set.Provider)
+	if set.Service != "" {
+		label += ":" + set.Service
+	}
+	if set.Region != "" {
+		label += "@" + set.Region
+	}
+	return label
+}
+
+// labelledPrefix is a prefix with the label of the first set listing it.
+type labelledPrefix struct {
+	prefix netip.Prefix
+	label  string
+}
+
+// uniquePrefixes lists the prefixes of all sets once, in set order. Servers
+// reject the same network twice in one map, so the first set wins.
+func uniquePrefixes(sets []RangeSet) []labelledPrefix {
+	seen := make(map[netip.Prefix]bool)
+	var prefixes []labelledPrefix
+	for _, set := range sets {
+		for _, prefix := range set.Prefixes {
+			if seen[prefix] {
+				continue
+			}
+			seen[prefix] = true
+			prefixes = append(prefixes, labelledPrefix{prefix: prefix, label: set.Label()})
+		}
+	}
+	return prefixes
+}
+
+// renderNginxGeo writes an nginx geo block mapping the client address to the
+// provider label in $cloudip_provider, empty for other clients.
+func renderNginxGeo(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	builder.WriteString("geo $cloudip_provider {\n")
+	builder.WriteString("\tdefault \"\";\n")
+	for _, entry := range uniquePrefixes(sets) {
+		fmt.Fprintf(&builder, "\t%s \"%s\";\n", entry.prefix, entry.label)
+	}
+	builder.WriteString("}\n")
+	return writeExport(w, "nginx-geo", sets, builder.String())
+}
+
+// renderNginxRealIP writes set_real_ip_from directives trusting the provider
+// ranges as proxies. The real_ip_header directive is left to the server config.
+func renderNginxRealIP(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	for _, entry := range uniquePrefixes(sets) {
+		fmt.Fprintf(&builder, "set_real_ip_from %s;\n", entry.prefix)
+	}
+	return writeExport(w, "nginx-realip", sets, builder.String())
+}
+
+// renderHaproxyMap writes a map file for map_ip, mapping every prefix to the
+// provider label.
+func renderHaproxyMap(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	for _, entry := range uniquePrefixes(sets) {
+		fmt.Fprintf(&builder, "%s %s\n", entry.prefix, entry.label)
+	}
+	return writeExport(w, "haproxy-map", sets, builder.String())
+}
+
+// renderHaproxyACL writes a pattern file for "acl name src -f file".
+func renderHaproxyACL(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	for _, entry := range uniquePrefixes(sets) {
+		fmt.Fprintf(&builder, "%s\n", entry.prefix)
+	}
+	return writeExport(w, "haproxy-acl", sets, builder.String())
+}
+
+// renderApache writes a RequireAny block allowing the provider ranges.
+func renderApache(w io.Writer, sets []RangeSet, _ Options) error {
+	var builder strings.Builder
+	builder.WriteString("<RequireAny>\n")
+	for _, entry := range uniquePrefixes(sets) {
+		fmt.Fprintf(&builder, "\tRequire ip %s\n", entry.prefix)
+	}
+	builder.WriteString("</RequireAny>\n")
+	return writeExport(w, "apache", sets, builder.String())
+}
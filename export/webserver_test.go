@@ -0,0 +1,94 @@
+package export
+
+import (
+	"bytes"
+	"cloudip/common"
+	"net/netip"
+	"testing"
+)
+
+func testWebServerSets() []RangeSet {
+	sets := testRangeSets()
+	return append(sets, NewRangeSets(RangeSet{Provider: common.AWS, Service: "CLOUDFRONT"}, []netip.Prefix{
+		netip.MustParsePrefix("13.32.0.0/15"),
+		netip.MustParsePrefix("103.21.244.0/22"),
+	})...)
+}
+
+func TestRenderWebServerFormats(t *testing.T) {
+	header := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: cloudflare (signature 1760000000)\n" +
+		"# Source: aws CLOUDFRONT\n"
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "nginx-geo",
+			expected: "geo $cloudip_provider {\n" +
+				"\tdefault \"\";\n" +
+				"\t103.21.244.0/22 \"cloudflare\";\n" +
+				"\t173.245.48.0/23 \"cloudflare\";\n" +
+				"\t2400:cb00::/32 \"cloudflare\";\n" +
+				"\t13.32.0.0/15 \"aws:CLOUDFRONT\";\n" +
+				"}\n",
+		},
+		{
+			format: "nginx-realip",
+			expected: "set_real_ip_from 103.21.244.0/22;\n" +
+				"set_real_ip_from 173.245.48.0/23;\n" +
+				"set_real_ip_from 2400:cb00::/32;\n" +
+				"set_real_ip_from 13.32.0.0/15;\n",
+		},
+		{
+			format: "haproxy-map",
+			expected: "103.21.244.0/22 cloudflare\n" +
+				"173.245.48.0/23 cloudflare\n" +
+				"2400:cb00::/32 cloudflare\n" +
+				"13.32.0.0/15 aws:CLOUDFRONT\n",
+		},
+		{
+			format: "haproxy-acl",
+			expected: "103.21.244.0/22\n" +
+				"173.245.48.0/23\n" +
+				"2400:cb00::/32\n" +
+				"13.32.0.0/15\n",
+		},
+		{
+			format: "apache",
+			expected: "<RequireAny>\n" +
+				"\tRequire ip 103.21.244.0/22\n" +
+				"\tRequire ip 173.245.48.0/23\n" +
+				"\tRequire ip 2400:cb00::/32\n" +
+				"\tRequire ip 13.32.0.0/15\n" +
+				"</RequireAny>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output := new(bytes.Buffer)
+			if err := Render(output, tt.format, testWebServerSets(), Options{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.String() != header+tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", header+tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestRenderWebServerFormatsFiltersFamily(t *testing.T) {
+	output := new(bytes.Buffer)
+	if err := Render(output, "haproxy-acl", testWebServerSets(), Options{Family: IPv6}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "# Generated by cloudip export. Do not edit.\n" +
+		"# Source: cloudflare (signature 1760000000)\n" +
+		"2400:cb00::/32\n"
+	if output.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
@@ -10,6 +10,7 @@ var appDir = util.GetAppDir(common.AppName)
 
 const DataFile = "aws.json"
 const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
 
 func getDataUrl() string {
 	return "https://ip-ranges.amazonaws.com/ip-ranges.json"
@@ -18,3 +19,4 @@ func getDataUrl() string {
 var ProviderDirectory = fmt.Sprintf("%s/%s", appDir, "aws")
 var DataFilePathAws = fmt.Sprintf("%s/%s", ProviderDirectory, DataFile)
 var MetadataFilePathAws = fmt.Sprintf("%s/%s", ProviderDirectory, MetadataFile)
+var ArchiveDirectoryAws = fmt.Sprintf("%s/%s", ProviderDirectory, ArchiveDir)
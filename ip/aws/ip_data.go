@@ -2,6 +2,7 @@ package aws
 
 import (
 	"cloudip/common"
+	"cloudip/ip/provider"
 	"cloudip/util"
 	"errors"
 	"fmt"
@@ -17,6 +18,7 @@ type IpDataManagerAws struct {
 	DataFilePath string
 	IpRange      IpRangeDataAws
 	UpdatePolicy common.UpdatePolicy
+	Archive      *common.DataArchive
 }
 
 type IpRangeDataAws struct {
@@ -61,15 +63,20 @@ func (ipDataManagerAws *IpDataManagerAws) downloadData() error {
 		return errors.New("cannot get DataURI")
 	}
 
+	return ipDataManagerAws.source().Update(ipDataManagerAws.downloadDataFile)
+}
+
+// downloadDataFile replaces the data file and returns its signature.
+func (ipDataManagerAws *IpDataManagerAws) downloadDataFile() (string, error) {
 	headers, err := util.DownloadFromUrlToFileWithHeaders(ipDataManagerAws.DataURI, ipDataManagerAws.DataFilePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	signature, currentLastModified, err := awsSignatureFromHeaders(headers)
 	if err != nil {
 		util.PrintErrorTrace(err)
-		return err
+		return "", err
 	}
 
 	signatureExpired := metadataManager.IsSignatureExpired(signature)
@@ -81,13 +88,25 @@ func (ipDataManagerAws *IpDataManagerAws) downloadData() error {
 	if err := metadataManager.Write(&metadata); err != nil {
 		err = util.ErrorWithInfo(err, "error writing metadata")
 		util.PrintErrorTrace(err)
-		return err
+		return "", err
 	}
 	if signatureExpired {
 		common.VerboseOutput(fmt.Sprintf("AWS IP ranges updated [%s]", util.FormatToTimestamp(currentLastModified)))
 	}
 
-	return nil
+	return signature, nil
+}
+
+// source describes the AWS data file for loading and archiving its ranges.
+func (ipDataManagerAws *IpDataManagerAws) source() provider.DataSource[IpRangeDataAws] {
+	return provider.DataSource[IpRangeDataAws]{
+		Load:     ipDataManagerAws.LoadIpData,
+		Read:     ipDataManagerAws.readDataFile,
+		Convert:  rangesFromData,
+		Files:    []string{ipDataManagerAws.DataFilePath},
+		Metadata: metadataManager,
+		Archive:  ipDataManagerAws.Archive,
+	}
 }
 
 func (ipDataManagerAws *IpDataManagerAws) SetUpdatePolicy(policy common.UpdatePolicy) {
@@ -161,6 +180,16 @@ func (ipDataManagerAws *IpDataManagerAws) LoadIpData() (*IpRangeDataAws, error)
 		return &ipDataManagerAws.IpRange, nil
 	}
 
+	awsIpRangeData, err := ipDataManagerAws.readDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	ipDataManagerAws.IpRange = *awsIpRangeData
+	return &ipDataManagerAws.IpRange, nil
+}
+
+func (ipDataManagerAws *IpDataManagerAws) readDataFile() (*IpRangeDataAws, error) {
 	awsIpRangeData := IpRangeDataAws{}
 	ipDataFile, err := os.Open(ipDataManagerAws.DataFilePath)
 	if err != nil {
@@ -173,8 +202,7 @@ func (ipDataManagerAws *IpDataManagerAws) LoadIpData() (*IpRangeDataAws, error)
 		return nil, util.ErrorWithInfo(err, "error reading data file")
 	}
 
-	ipDataManagerAws.IpRange = awsIpRangeData
-	return &ipDataManagerAws.IpRange, nil
+	return &awsIpRangeData, nil
 }
 
 var ipDataManagerAws = &IpDataManagerAws{
@@ -182,4 +210,5 @@ var ipDataManagerAws = &IpDataManagerAws{
 	DataFile:     DataFile,
 	DataFilePath: DataFilePathAws,
 	IpRange:      IpRangeDataAws{},
+	Archive:      &common.DataArchive{Provider: common.AWS, Dir: ArchiveDirectoryAws},
 }
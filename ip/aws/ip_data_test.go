@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"encoding/json"
 	"path/filepath"
 	"testing"
 )
@@ -15,3 +16,32 @@ func TestAWSLoadIpDataReturnsErrorForMissingFile(t *testing.T) {
 		t.Fatal("LoadIpData() error = nil, want error")
 	}
 }
+
+func TestAWSRangesFromDataOrdersAmazonLast(t *testing.T) {
+	data := &IpRangeDataAws{}
+	if err := json.Unmarshal([]byte(`{
+		"prefixes": [
+			{"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "AMAZON", "network_border_group": "ap-northeast-2"},
+			{"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "S3", "network_border_group": "ap-northeast-2"}
+		],
+		"ipv6_prefixes": [
+			{"ipv6_prefix": "2600:1f00::/24", "region": "us-east-1", "service": "AMAZON", "network_border_group": "us-east-1"}
+		]
+	}`), data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	ranges := rangesFromData(data)
+	if len(ranges) != 3 {
+		t.Fatalf("len(ranges) = %d, want 3", len(ranges))
+	}
+	if ranges[0].Service != "S3" {
+		t.Fatalf("first range service = %q, want S3", ranges[0].Service)
+	}
+	if ranges[0].Attributes["network_border_group"] != "ap-northeast-2" {
+		t.Fatalf("network_border_group = %q, want ap-northeast-2", ranges[0].Attributes["network_border_group"])
+	}
+	if ranges[2].Prefix != "2600:1f00::/24" {
+		t.Fatalf("last range prefix = %q, want 2600:1f00::/24", ranges[2].Prefix)
+	}
+}
@@ -1,39 +1,49 @@
 package aws
 
 import (
+	"cloudip/common"
 	"cloudip/ip/provider"
-	"cloudip/util"
+	"sort"
 )
 
 type AWSProvider struct {
-	*provider.BaseProvider
+	*provider.DataProvider[IpRangeDataAws]
 }
 
 func NewAWSProvider() *AWSProvider {
 	return &AWSProvider{
-		BaseProvider: provider.NewBaseProvider("AWS", ipDataManagerAws, func(bp *provider.BaseProvider) error {
-			awsIpRangeData, err := ipDataManagerAws.LoadIpData()
-			if err != nil {
-				return err
-			}
+		DataProvider: provider.NewDataProvider("AWS", ipDataManagerAws, ipDataManagerAws.source),
+	}
+}
 
-			for _, prefix := range awsIpRangeData.Prefixes {
-				if err := bp.AddIPv4Range(prefix.IpPrefix); err != nil {
-					util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+prefix.IpPrefix))
-					continue
-				}
-			}
+// rangesFromData converts the AWS data file into ranges. Every prefix is also
+// published under the AMAZON service, so those entries are ordered last to let
+// the more specific service win when the same prefix is added again.
+func rangesFromData(data *IpRangeDataAws) []common.RangeInfo {
+	ranges := make([]common.RangeInfo, 0, len(data.Prefixes)+len(data.Ipv6Prefixes))
+	for _, prefix := range data.Prefixes {
+		ranges = append(ranges, newRangeInfo(prefix.IpPrefix, prefix.Region, prefix.Service, prefix.NetworkBorderGroup))
+	}
+	for _, prefix := range data.Ipv6Prefixes {
+		ranges = append(ranges, newRangeInfo(prefix.Ipv6Prefix, prefix.Region, prefix.Service, prefix.NetworkBorderGroup))
+	}
 
-			for _, prefix := range awsIpRangeData.Ipv6Prefixes {
-				if err := bp.AddIPv6Range(prefix.Ipv6Prefix); err != nil {
-					util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+prefix.Ipv6Prefix))
-					continue
-				}
-			}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Service != "AMAZON" && ranges[j].Service == "AMAZON"
+	})
+	return ranges
+}
 
-			return nil
-		}),
+func newRangeInfo(prefix, region, service, networkBorderGroup string) common.RangeInfo {
+	info := common.RangeInfo{
+		Prefix:  prefix,
+		Region:  region,
+		Service: service,
+	}
+	if networkBorderGroup != "" {
+		info.Attributes = map[string]string{"network_border_group": networkBorderGroup}
 	}
+	return info
 }
 
 var Provider = NewAWSProvider()
@@ -15,6 +15,7 @@ var appDir = util.GetAppDir(common.AppName)
 
 const DataFile = "azure.json"
 const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
 
 var dataRequestOnce sync.Once
 var dataUrl string = "" // return empty string when error
@@ -65,3 +66,4 @@ func getDataUrl() string {
 var ProviderDirectory = fmt.Sprintf("%s/%s", appDir, "azure")
 var DataFilePathAzure = fmt.Sprintf("%s/%s", ProviderDirectory, DataFile)
 var MetadataFilePathAzure = fmt.Sprintf("%s/%s", ProviderDirectory, MetadataFile)
+var ArchiveDirectoryAzure = fmt.Sprintf("%s/%s", ProviderDirectory, ArchiveDir)
@@ -2,6 +2,7 @@ package azure
 
 import (
 	"cloudip/common"
+	"cloudip/ip/provider"
 	"cloudip/util"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ type IpDataManagerAzure struct {
 	DataFilePath string
 	IpRange      IpRangeDataAzure
 	UpdatePolicy common.UpdatePolicy
+	Archive      *common.DataArchive
 	dataURIMu    sync.Mutex
 }
 
@@ -89,16 +91,21 @@ func (ipDataManagerAzure *IpDataManagerAzure) downloadData() error {
 		return err
 	}
 
+	return ipDataManagerAzure.source().Update(ipDataManagerAzure.downloadDataFile)
+}
+
+// downloadDataFile replaces the data file and returns its signature.
+func (ipDataManagerAzure *IpDataManagerAzure) downloadDataFile() (string, error) {
 	headers, err := util.DownloadFromUrlToFileWithHeaders(ipDataManagerAzure.DataURI, ipDataManagerAzure.DataFilePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	currentLastModified, err := time.Parse(time.RFC1123, headers.Get("Last-Modified"))
 	if err != nil {
 		err = util.ErrorWithInfo(err, "error parsing Date header")
 		util.PrintErrorTrace(err)
-		return err
+		return "", err
 	}
 	signature := common.LastModifiedSignature(currentLastModified)
 
@@ -111,13 +118,26 @@ func (ipDataManagerAzure *IpDataManagerAzure) downloadData() error {
 	if err := metadataManager.Write(&metadata); err != nil {
 		err = util.ErrorWithInfo(err, "error writing metadata")
 		util.PrintErrorTrace(err)
-		return err
+		return "", err
 	}
 	if signatureExpired {
 		common.VerboseOutput(fmt.Sprintf("Azure IP ranges updated [%s]", util.FormatToTimestamp(currentLastModified)))
 	}
 
-	return nil
+	return signature, nil
+}
+
+// source describes the Azure service tags file for loading and archiving its
+// ranges.
+func (ipDataManagerAzure *IpDataManagerAzure) source() provider.DataSource[IpRangeDataAzure] {
+	return provider.DataSource[IpRangeDataAzure]{
+		Load:     ipDataManagerAzure.LoadIpData,
+		Read:     ipDataManagerAzure.readDataFile,
+		Convert:  rangesFromData,
+		Files:    []string{ipDataManagerAzure.DataFilePath},
+		Metadata: metadataManager,
+		Archive:  ipDataManagerAzure.Archive,
+	}
 }
 
 func (ipDataManagerAzure *IpDataManagerAzure) SetUpdatePolicy(policy common.UpdatePolicy) {
@@ -174,6 +194,16 @@ func (ipDataManagerAzure *IpDataManagerAzure) LoadIpData() (*IpRangeDataAzure, e
 		return &ipDataManagerAzure.IpRange, nil
 	}
 
+	azureIpRangeData, err := ipDataManagerAzure.readDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	ipDataManagerAzure.IpRange = *azureIpRangeData
+	return &ipDataManagerAzure.IpRange, nil
+}
+
+func (ipDataManagerAzure *IpDataManagerAzure) readDataFile() (*IpRangeDataAzure, error) {
 	azureIpRangeData := IpRangeDataAzure{}
 	ipDataFile, err := os.Open(ipDataManagerAzure.DataFilePath)
 	if err != nil {
@@ -186,12 +216,12 @@ func (ipDataManagerAzure *IpDataManagerAzure) LoadIpData() (*IpRangeDataAzure, e
 		return nil, util.ErrorWithInfo(err, "error reading data file")
 	}
 
-	ipDataManagerAzure.IpRange = azureIpRangeData
-	return &ipDataManagerAzure.IpRange, nil
+	return &azureIpRangeData, nil
 }
 
 var ipDataManagerAzure = &IpDataManagerAzure{
 	DataFile:     DataFile,
 	DataFilePath: DataFilePathAzure,
 	IpRange:      IpRangeDataAzure{},
+	Archive:      &common.DataArchive{Provider: common.Azure, Dir: ArchiveDirectoryAzure},
 }
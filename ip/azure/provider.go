@@ -1,36 +1,40 @@
 package azure
 
 import (
+	"cloudip/common"
 	"cloudip/ip/provider"
-	"cloudip/util"
-	"fmt"
 )
 
 type AzureProvider struct {
-	*provider.BaseProvider
+	*provider.DataProvider[IpRangeDataAzure]
 }
 
 func NewAzureProvider() *AzureProvider {
 	return &AzureProvider{
-		BaseProvider: provider.NewBaseProvider("Azure", ipDataManagerAzure, func(bp *provider.BaseProvider) error {
-			azureIpRangeData, err := ipDataManagerAzure.LoadIpData()
-			if err != nil {
-				return err
-			}
-
-			for _, dataObject := range azureIpRangeData.Values {
-				for _, prefix := range dataObject.Properties.AddressPrefixes {
-					err := bp.AddCIDRRange(prefix)
-					if err != nil {
-						util.PrintErrorTrace(util.ErrorWithInfo(err, fmt.Sprintf("error parsing CIDR: %s", prefix)))
-						continue
-					}
-				}
-			}
+		DataProvider: provider.NewDataProvider("Azure", ipDataManagerAzure, ipDataManagerAzure.source),
+	}
+}
 
-			return nil
-		}),
+// rangesFromData converts the Azure service tags into ranges. A prefix is
+// usually listed under several tags, and the region and service are merged
+// from whichever tags provide them.
+func rangesFromData(data *IpRangeDataAzure) []common.RangeInfo {
+	ranges := make([]common.RangeInfo, 0, len(data.Values))
+	for _, dataObject := range data.Values {
+		attributes := map[string]string{"tag": dataObject.Name}
+		if dataObject.Properties.Platform != "" {
+			attributes["platform"] = dataObject.Properties.Platform
+		}
+		for _, prefix := range dataObject.Properties.AddressPrefixes {
+			ranges = append(ranges, common.RangeInfo{
+				Prefix:     prefix,
+				Region:     dataObject.Properties.Region,
+				Service:    dataObject.Properties.SystemService,
+				Attributes: attributes,
+			})
+		}
 	}
+	return ranges
 }
 
 var Provider = NewAzureProvider()
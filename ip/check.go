@@ -3,15 +3,31 @@ package ip
 import (
 	"cloudip/common"
 	"cloudip/ip/provider"
+	"cloudip/util"
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
+	"strings"
+	"sync"
 )
 
+// ProviderLoader returns providers to check after the ones a checker was
+// built with, in the order they are checked.
+type ProviderLoader func() (map[common.CloudProvider]provider.CloudProvider, []common.CloudProvider, error)
+
 type IPChecker struct {
 	providers     map[common.CloudProvider]provider.CloudProvider
 	providerOrder []common.CloudProvider
+	loader        ProviderLoader
 	updatePolicy  common.UpdatePolicy
+	matchAll      bool
+	strategy      common.MatchStrategy
+	resolver      Resolver
+	index         *Index
+	indexed       map[common.CloudProvider]bool
+	indexErr      error
+	indexLock     sync.Mutex
 }
 
 func NewIPChecker(providers map[common.CloudProvider]provider.CloudProvider, order []common.CloudProvider) *IPChecker {
@@ -19,9 +35,56 @@ func NewIPChecker(providers map[common.CloudProvider]provider.CloudProvider, ord
 		providers:     providers,
 		providerOrder: order,
 		updatePolicy:  common.DefaultUpdatePolicy(),
+		strategy:      common.StrategyOrdered,
+		resolver:      net.DefaultResolver,
 	}
 }
 
+// Providers returns the registered providers in provider order.
+func (c *IPChecker) Providers() []common.CloudProvider {
+	providers := make([]common.CloudProvider, 0, len(c.providers))
+	for _, providerType := range c.providerOrder {
+		if _, exists := c.providers[providerType]; exists {
+			providers = append(providers, providerType)
+		}
+	}
+	return providers
+}
+
+// Provider returns the registered provider of the given type.
+func (c *IPChecker) Provider(providerType common.CloudProvider) (provider.CloudProvider, bool) {
+	p, exists := c.providers[providerType]
+	return p, exists
+}
+
+// SetProviderLoader sets the loader of providers that are added when the
+// checker is about to be used rather than when it is built, such as the
+// providers of a config file that only the commands checking addresses need.
+func (c *IPChecker) SetProviderLoader(loader ProviderLoader) {
+	c.loader = loader
+}
+
+// LoadProviders adds the providers of the loader, once.
+func (c *IPChecker) LoadProviders() error {
+	if c.loader == nil {
+		return nil
+	}
+	providers, order, err := c.loader()
+	if err != nil {
+		return err
+	}
+	c.loader = nil
+
+	if c.providers == nil {
+		c.providers = make(map[common.CloudProvider]provider.CloudProvider, len(providers))
+	}
+	for _, providerType := range order {
+		c.providers[providerType] = providers[providerType]
+		c.providerOrder = append(c.providerOrder, providerType)
+	}
+	return nil
+}
+
 func (c *IPChecker) SetUpdatePolicy(policy common.UpdatePolicy) {
 	c.updatePolicy = policy
 	for _, p := range c.providers {
@@ -31,29 +94,124 @@ func (c *IPChecker) SetUpdatePolicy(policy common.UpdatePolicy) {
 	}
 }
 
+// SetMatchAll makes the checker evaluate every provider and report all
+// matches instead of stopping at the first provider in order.
+func (c *IPChecker) SetMatchAll(matchAll bool) {
+	c.matchAll = matchAll
+}
+
+// SetStrategy sets how the winning provider is chosen among several matches.
+func (c *IPChecker) SetStrategy(strategy common.MatchStrategy) {
+	c.strategy = strategy
+}
+
+// SetResolver sets the resolver used for hostname inputs.
+func (c *IPChecker) SetResolver(resolver Resolver) {
+	c.resolver = resolver
+}
+
 func (c *IPChecker) Check(ips []string) []common.Result {
 	results := make([]common.Result, len(ips))
 
 	for index, ip := range ips {
-		provider, err := c.checkCloudIp(ip)
-		results[index] = common.Result{
-			Ip:       ip,
-			Provider: provider,
-			Error:    err,
-		}
+		results[index] = c.CheckInput(ip)
 	}
 
 	return results
 }
 
-func (c *IPChecker) checkCloudIp(ip string) (common.CloudProvider, error) {
+// CheckInput classifies a single IP, prefix, address range or hostname. It
+// lets callers stream results without collecting them first.
+func (c *IPChecker) CheckInput(input string) common.Result {
+	if prefixes, isBlock, err := parseBlock(input); isBlock {
+		return c.checkBlock(input, prefixes, err)
+	}
+	if net.ParseIP(input) == nil && isHostname(input) {
+		return c.checkHost(input)
+	}
+	return c.checkIP(input)
+}
+
+// checkIP classifies a single address. A special-purpose address that no
+// provider matched is reported as reserved, even when some providers failed
+// to load, since the reserved table needs no provider data. An IPv6
+// transition address that embeds an IPv4 address also reports the result of
+// the IPv4 address.
+func (c *IPChecker) checkIP(ip string) common.Result {
+	addr, addrErr := netip.ParseAddr(ip)
+	matches, err := c.checkCloudIp(ip)
+	if len(matches) == 0 && addrErr == nil {
+		if info, reserved := LookupReserved(addr); reserved {
+			return common.Result{Ip: ip, Provider: common.Reserved, Range: info}
+		}
+	}
+
+	result := common.Result{
+		Ip:    ip,
+		Error: err,
+	}
+	if len(matches) > 0 {
+		winner := c.selectMatch(matches)
+		result.Provider = winner.Provider
+		result.Range = winner.Range
+		result.Strategy = c.strategy
+	}
+	if c.matchAll {
+		result.Matches = matches
+	}
+	if addrErr == nil {
+		if embedded, embedding, ok := util.EmbeddedIPv4(addr); ok {
+			embeddedResult := c.checkIP(embedded.String())
+			result.Embedded = &embeddedResult
+			result.Embedding = embedding
+		}
+	}
+	return result
+}
+
+// checkHost resolves the hostname and checks every address it resolved to.
+func (c *IPChecker) checkHost(host string) common.Result {
+	result := common.Result{Ip: host, Host: host}
+	addresses, err := resolveHost(c.resolver, host)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.Addresses = make([]common.Result, 0, len(addresses))
+	for _, address := range addresses {
+		result.Addresses = append(result.Addresses, c.checkIP(address))
+	}
+	return result
+}
+
+// checkCloudIp returns the matching providers in provider order. The ordered
+// strategy only needs the first match, so the rest are skipped unless the
+// checker matches all providers.
+func (c *IPChecker) checkCloudIp(ip string) ([]common.Match, error) {
 	parsedIP := net.ParseIP(ip)
 	if parsedIP == nil {
-		return "", fmt.Errorf("error parsing IP: %s", ip)
+		return nil, fmt.Errorf("error parsing IP: %s", ip)
 	}
 
-	var providerErr error
+	index, indexErr := c.ensureIndex()
+	indexMatches := index.LookupParsedIP(parsedIP)
+
+	var matches []common.Match
+	providerErr := indexErr
 	for _, providerType := range c.providerOrder {
+		if c.indexed[providerType] {
+			match, isMatch := findMatch(indexMatches, providerType)
+			if !isMatch {
+				continue
+			}
+			matches = append(matches, match)
+			if !c.matchAll && c.strategy != common.StrategyLongestPrefix {
+				break
+			}
+			continue
+		}
+
 		p, exists := c.providers[providerType]
 		if !exists {
 			continue
@@ -65,15 +223,173 @@ func (c *IPChecker) checkCloudIp(ip string) (common.CloudProvider, error) {
 			continue
 		}
 
-		isMatch, err := p.CheckParsedIP(parsedIP)
+		rangeInfo, isMatch, err := lookupParsedIP(p, parsedIP)
 		if err != nil {
 			providerErr = errors.Join(providerErr, fmt.Errorf("%s check: %w", providerType, err))
 			continue
 		}
 
-		if isMatch {
-			return providerType, nil
+		if !isMatch {
+			continue
+		}
+		matches = append(matches, common.Match{Provider: providerType, Range: rangeInfo})
+		if !c.matchAll && c.strategy != common.StrategyLongestPrefix {
+			break
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+	return nil, providerErr
+}
+
+// parseBlock parses CIDR prefixes such as "13.32.0.0/15" and address ranges
+// such as "10.0.0.1-10.0.0.200". It reports false for anything else so the
+// input is checked as a single IP.
+func parseBlock(input string) ([]netip.Prefix, bool, error) {
+	if strings.Contains(input, "/") {
+		prefix, err := util.ParsePrefix(input)
+		if err != nil {
+			return nil, true, fmt.Errorf("error parsing prefix: %s", input)
+		}
+		return []netip.Prefix{prefix}, true, nil
+	}
+
+	start, end, found := strings.Cut(input, "-")
+	if !found {
+		return nil, false, nil
+	}
+	if _, err := netip.ParseAddr(start); err != nil {
+		return nil, false, nil
+	}
+	if _, err := netip.ParseAddr(end); err != nil {
+		return nil, false, nil
+	}
+	prefixes, err := util.ParseAddressRange(input)
+	if err != nil {
+		return nil, true, fmt.Errorf("error parsing address range: %w", err)
+	}
+	return prefixes, true, nil
+}
+
+// checkBlock reports how a prefix or address range is covered by providers.
+// Every overlapping provider range is listed; the result names the first
+// provider in order with the best coverage.
+func (c *IPChecker) checkBlock(input string, prefixes []netip.Prefix, err error) common.Result {
+	result := common.Result{Ip: input, Error: err}
+	if err != nil {
+		return result
+	}
+
+	index, indexErr := c.ensureIndex()
+	matches := index.LookupPrefixes(prefixes)
+	for _, providerType := range c.providerOrder {
+		if _, exists := c.providers[providerType]; exists && !c.indexed[providerType] {
+			indexErr = errors.Join(indexErr, fmt.Errorf("%s: prefix lookups are not supported", providerType))
+		}
+	}
+
+	result.Coverage = common.CoverageNone
+	result.Matches = matches
+	for _, match := range matches {
+		if result.Coverage == common.CoverageNone || (result.Coverage == common.CoveragePartial && match.Coverage == common.CoverageFull) {
+			result.Provider = match.Provider
+			result.Range = match.Range
+			result.Coverage = match.Coverage
+		}
+	}
+	if len(matches) == 0 {
+		result.Error = indexErr
+	}
+	return result
+}
+
+// ensureIndex builds the combined index on first use. Providers that can list
+// their ranges are initialized once and served from the index; the others are
+// still checked one by one. Initialization errors are kept and reported with
+// every lookup that finds no match.
+func (c *IPChecker) ensureIndex() (*Index, error) {
+	c.indexLock.Lock()
+	defer c.indexLock.Unlock()
+
+	if c.index != nil {
+		return c.index, c.indexErr
+	}
+
+	index := NewIndex(c.providerOrder)
+	indexed := make(map[common.CloudProvider]bool)
+	var indexErr error
+	for _, providerType := range c.providerOrder {
+		p, exists := c.providers[providerType]
+		if !exists {
+			continue
+		}
+		lister, ok := p.(provider.RangeLister)
+		if !ok {
+			continue
+		}
+
+		indexed[providerType] = true
+		if err := p.Initialize(); err != nil {
+			indexErr = errors.Join(indexErr, fmt.Errorf("%s initialize: %w", providerType, err))
+			continue
+		}
+		if err := index.Add(providerType, lister.Ranges()); err != nil {
+			indexErr = errors.Join(indexErr, fmt.Errorf("%s index: %w", providerType, err))
+		}
+	}
+
+	c.index = index
+	c.indexed = indexed
+	c.indexErr = indexErr
+	return c.index, c.indexErr
+}
+
+func findMatch(matches []common.Match, providerType common.CloudProvider) (common.Match, bool) {
+	for _, match := range matches {
+		if match.Provider == providerType {
+			return match, true
+		}
+	}
+	return common.Match{}, false
+}
+
+// lookupParsedIP reports the matched range when the provider supports it and
+// falls back to a plain membership check otherwise.
+func lookupParsedIP(p provider.CloudProvider, parsedIP net.IP) (common.RangeInfo, bool, error) {
+	if lookuper, ok := p.(provider.RangeLookuper); ok {
+		return lookuper.LookupParsedIP(parsedIP)
+	}
+
+	isMatch, err := p.CheckParsedIP(parsedIP)
+	return common.RangeInfo{}, isMatch, err
+}
+
+// selectMatch returns the winning match according to the checker strategy.
+// Matches with equally specific prefixes keep the provider order.
+func (c *IPChecker) selectMatch(matches []common.Match) common.Match {
+	winner := matches[0]
+	if c.strategy != common.StrategyLongestPrefix {
+		return winner
+	}
+
+	winnerLength := prefixLength(winner.Range)
+	for _, match := range matches[1:] {
+		if length := prefixLength(match.Range); length > winnerLength {
+			winner = match
+			winnerLength = length
 		}
 	}
-	return "", providerErr
+	return winner
+}
+
+// prefixLength returns the prefix length of the range, or -1 when the
+// provider did not report the matched prefix.
+func prefixLength(info common.RangeInfo) int {
+	_, ipNet, err := net.ParseCIDR(info.Prefix)
+	if err != nil {
+		return -1
+	}
+	ones, _ := ipNet.Mask.Size()
+	return ones
 }
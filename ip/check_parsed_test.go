@@ -51,8 +51,8 @@ func TestCheckCloudIP_UsesParsedProviderPath(t *testing.T) {
 		t.Fatalf("checkCloudIp returned unexpected error: %v", err)
 	}
 
-	if got != common.AWS {
-		t.Fatalf("checkCloudIp returned %q, want %q", got, common.AWS)
+	if len(got) != 1 || got[0].Provider != common.AWS {
+		t.Fatalf("checkCloudIp returned %v, want %q", got, common.AWS)
 	}
 
 	if mockProvider.initializeCalls != 1 {
@@ -105,16 +105,25 @@ func TestCheckerCheck(t *testing.T) {
 		},
 		{
 			name: "No match found",
-			ip:   "172.16.1.1",
+			ip:   "8.8.8.8",
 			mockProviders: map[common.CloudProvider]provider.CloudProvider{
 				common.AWS: newMockProvider("AWS", false, false, false),
 				common.GCP: newMockProvider("GCP", false, false, false),
 			},
 			expectedProvider: "",
 		},
+		{
+			name: "Special-purpose address without a match is reserved",
+			ip:   "172.16.1.1",
+			mockProviders: map[common.CloudProvider]provider.CloudProvider{
+				common.AWS: newMockProvider("AWS", false, false, false),
+				common.GCP: newMockProvider("GCP", false, false, false),
+			},
+			expectedProvider: common.Reserved,
+		},
 		{
 			name: "Initialization error",
-			ip:   "192.168.1.1",
+			ip:   "8.8.8.8",
 			mockProviders: map[common.CloudProvider]provider.CloudProvider{
 				common.AWS: newMockProvider("AWS", true, false, true),
 			},
@@ -122,7 +131,7 @@ func TestCheckerCheck(t *testing.T) {
 		},
 		{
 			name: "CheckParsedIP error",
-			ip:   "192.168.1.1",
+			ip:   "8.8.8.8",
 			mockProviders: map[common.CloudProvider]provider.CloudProvider{
 				common.AWS: newMockProvider("AWS", true, true, false),
 			},
@@ -148,13 +157,22 @@ func TestCheckerCheck(t *testing.T) {
 		},
 		{
 			name: "Provider errors return error when no provider matches",
-			ip:   "10.1.1.1",
+			ip:   "8.8.8.8",
 			mockProviders: map[common.CloudProvider]provider.CloudProvider{
 				common.AWS: newMockProvider("AWS", true, false, true),
 				common.GCP: newMockProvider("GCP", true, true, false),
 			},
 			expectError: true,
 		},
+		{
+			name: "Special-purpose address is reserved when a provider fails",
+			ip:   "192.168.1.1",
+			mockProviders: map[common.CloudProvider]provider.CloudProvider{
+				common.AWS: newMockProvider("AWS", true, false, true),
+				common.GCP: newMockProvider("GCP", false, true, false),
+			},
+			expectedProvider: common.Reserved,
+		},
 	}
 
 	for _, tt := range tests {
@@ -259,3 +277,188 @@ func TestCheckWithProviderOrder(t *testing.T) {
 		t.Errorf("Expected AWS, got %q", results[0].Provider)
 	}
 }
+
+func TestCheckReportsMatchedRange(t *testing.T) {
+	bp := provider.NewBaseProvider("AWS", &noopDataManager{}, func(bp *provider.BaseProvider) error {
+		if err := bp.AddRange(common.RangeInfo{Prefix: "3.5.0.0/16", Region: "us-east-1", Service: "AMAZON"}); err != nil {
+			return err
+		}
+		return bp.AddRange(common.RangeInfo{Prefix: "3.5.140.0/22", Region: "ap-northeast-2", Service: "S3"})
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: bp},
+		DefaultProviderOrder,
+	)
+
+	results := checker.Check([]string{"3.5.140.1", "3.5.1.1"})
+
+	if results[0].Range.Prefix != "3.5.140.0/22" || results[0].Range.Region != "ap-northeast-2" || results[0].Range.Service != "S3" {
+		t.Errorf("expected most specific range, got %+v", results[0].Range)
+	}
+	if results[1].Range.Prefix != "3.5.0.0/16" || results[1].Range.Service != "AMAZON" {
+		t.Errorf("expected covering range, got %+v", results[1].Range)
+	}
+}
+
+func TestCheckDecodesEmbeddedIPv4(t *testing.T) {
+	bp := provider.NewBaseProvider("AWS", &noopDataManager{}, func(bp *provider.BaseProvider) error {
+		if err := bp.AddRange(common.RangeInfo{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}); err != nil {
+			return err
+		}
+		return bp.AddRange(common.RangeInfo{Prefix: "2002::/16", Service: "6TO4-RELAY"})
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: bp},
+		DefaultProviderOrder,
+	)
+
+	results := checker.Check([]string{"64:ff9b::36e6:101", "2002:36e6:101::1", "2600:9000::1", "54.230.1.1"})
+
+	nat64 := results[0]
+	if nat64.Provider != "" || nat64.Embedded == nil || nat64.Embedding != util.EmbeddingNAT64 {
+		t.Fatalf("expected unknown NAT64 address with embedded result, got %+v", nat64)
+	}
+	if nat64.Embedded.Ip != "54.230.1.1" || nat64.Embedded.Provider != common.AWS || nat64.Embedded.Range.Service != "CLOUDFRONT" {
+		t.Errorf("expected embedded CloudFront address, got %+v", nat64.Embedded)
+	}
+
+	sixToFour := results[1]
+	if sixToFour.Range.Service != "6TO4-RELAY" || sixToFour.Embedding != util.Embedding6to4 || sixToFour.Embedded.Range.Service != "CLOUDFRONT" {
+		t.Errorf("expected both the IPv6 and the embedded classification, got %+v", sixToFour)
+	}
+
+	for _, result := range results[2:] {
+		if result.Embedded != nil || result.Embedding != "" {
+			t.Errorf("expected no embedded address for %s, got %+v", result.Ip, result.Embedded)
+		}
+	}
+}
+
+type noopDataManager struct{}
+
+func (m *noopDataManager) EnsureDataFile() error {
+	return nil
+}
+
+func TestCheckWithMatchAll(t *testing.T) {
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{
+			common.AWS:        newMockProvider("AWS", true, false, false),
+			common.GCP:        newMockProvider("GCP", false, false, false),
+			common.Cloudflare: newMockProvider("Cloudflare", true, false, false),
+			common.Azure:      newMockProvider("Azure", true, true, false),
+		},
+		DefaultProviderOrder,
+	)
+	checker.SetMatchAll(true)
+
+	results := checker.Check([]string{"192.168.1.1", "8.8.8.8"})
+
+	if results[0].Error != nil {
+		t.Fatalf("Unexpected error: %v", results[0].Error)
+	}
+	if len(results[0].Matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %v", results[0].Matches)
+	}
+	if results[0].Matches[0].Provider != common.AWS || results[0].Matches[1].Provider != common.Cloudflare {
+		t.Errorf("Expected matches in provider order, got %v", results[0].Matches)
+	}
+	if results[0].Provider != common.AWS {
+		t.Errorf("Expected first match as provider, got %q", results[0].Provider)
+	}
+
+	if len(results[1].Matches) != 0 || results[1].Provider != "" {
+		t.Errorf("Expected no matches, got %v", results[1].Matches)
+	}
+	if results[1].Error == nil {
+		t.Error("Expected provider error when nothing matches")
+	}
+}
+
+func TestCheckWithLongestPrefixStrategy(t *testing.T) {
+	newRangeProvider := func(name string, cidr string) provider.CloudProvider {
+		return provider.NewBaseProvider(name, &noopDataManager{}, func(bp *provider.BaseProvider) error {
+			return bp.AddCIDRRange(cidr)
+		})
+	}
+	providers := map[common.CloudProvider]provider.CloudProvider{
+		common.AWS:        newRangeProvider("AWS", "104.16.0.0/12"),
+		common.GCP:        newRangeProvider("GCP", "104.16.0.0/24"),
+		common.Cloudflare: newRangeProvider("Cloudflare", "104.16.0.0/13"),
+	}
+
+	tests := []struct {
+		name             string
+		strategy         common.MatchStrategy
+		ip               string
+		expectedProvider common.CloudProvider
+		expectedPrefix   string
+	}{
+		{"ordered picks first provider", common.StrategyOrdered, "104.16.0.1", common.AWS, "104.16.0.0/12"},
+		{"longest prefix picks most specific", common.StrategyLongestPrefix, "104.16.0.1", common.GCP, "104.16.0.0/24"},
+		{"longest prefix among remaining matches", common.StrategyLongestPrefix, "104.17.0.1", common.Cloudflare, "104.16.0.0/13"},
+		{"longest prefix with single match", common.StrategyLongestPrefix, "104.24.0.1", common.AWS, "104.16.0.0/12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewIPChecker(providers, DefaultProviderOrder)
+			checker.SetStrategy(tt.strategy)
+
+			result := checker.Check([]string{tt.ip})[0]
+			if result.Error != nil {
+				t.Fatalf("Unexpected error: %v", result.Error)
+			}
+			if result.Provider != tt.expectedProvider || result.Range.Prefix != tt.expectedPrefix {
+				t.Errorf("Got %q %q, expected %q %q", result.Provider, result.Range.Prefix, tt.expectedProvider, tt.expectedPrefix)
+			}
+			if result.Strategy != tt.strategy {
+				t.Errorf("Strategy mismatch: got %q, expected %q", result.Strategy, tt.strategy)
+			}
+			if len(result.Matches) != 0 {
+				t.Errorf("Expected matches to be reported only in match-all mode, got %v", result.Matches)
+			}
+		})
+	}
+}
+
+func TestLoadProvidersAddsProvidersOnce(t *testing.T) {
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{
+			common.AWS: newMockProvider("AWS", false, false, false),
+		},
+		[]common.CloudProvider{common.AWS},
+	)
+	calls := 0
+	checker.SetProviderLoader(func() (map[common.CloudProvider]provider.CloudProvider, []common.CloudProvider, error) {
+		calls++
+		return map[common.CloudProvider]provider.CloudProvider{
+			"corp": newMockProvider("Corp", true, false, false),
+		}, []common.CloudProvider{"corp"}, nil
+	})
+
+	for range 2 {
+		if err := checker.LoadProviders(); err != nil {
+			t.Fatalf("LoadProviders() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+
+	results := checker.Check([]string{"10.1.1.1"})
+	if len(results) != 1 || results[0].Provider != "corp" {
+		t.Errorf("Check() = %+v, want a match of the loaded provider", results)
+	}
+}
+
+func TestLoadProvidersReturnsLoaderError(t *testing.T) {
+	checker := NewIPChecker(nil, nil)
+	checker.SetProviderLoader(func() (map[common.CloudProvider]provider.CloudProvider, []common.CloudProvider, error) {
+		return nil, nil, errors.New("invalid config")
+	})
+
+	if err := checker.LoadProviders(); err == nil || err.Error() != "invalid config" {
+		t.Errorf("LoadProviders() error = %v, want the loader error", err)
+	}
+}
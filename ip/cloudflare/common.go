@@ -11,6 +11,7 @@ var appDir = util.GetAppDir(common.AppName)
 const DataFileV4 = "cloudflare-v4.txt"
 const DataFileV6 = "cloudflare-v6.txt"
 const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
 
 func getDataUrl() string {
 	// /client/v4 is the Cloudflare API version, not an IPv4-only endpoint.
@@ -21,3 +22,4 @@ var ProviderDirectory = fmt.Sprintf("%s/%s", appDir, "cloudflare")
 var DataFilePathCloudflareV4 = fmt.Sprintf("%s/%s", ProviderDirectory, DataFileV4)
 var DataFilePathCloudflareV6 = fmt.Sprintf("%s/%s", ProviderDirectory, DataFileV6)
 var MetadataFilePathCloudflare = fmt.Sprintf("%s/%s", ProviderDirectory, MetadataFile)
+var ArchiveDirectoryCloudflare = fmt.Sprintf("%s/%s", ProviderDirectory, ArchiveDir)
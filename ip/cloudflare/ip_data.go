@@ -2,6 +2,7 @@ package cloudflare
 
 import (
 	"cloudip/common"
+	"cloudip/ip/provider"
 	"cloudip/util"
 	"errors"
 	"io"
@@ -18,6 +19,7 @@ type IpDataManagerCloudflare struct {
 	DataFilePathV6 string
 	IpRange        IpRangeDataCloudflare
 	UpdatePolicy   common.UpdatePolicy
+	Archive        *common.DataArchive
 }
 
 type IpRangeDataCloudflare struct {
@@ -72,6 +74,13 @@ func (m *IpDataManagerCloudflare) writeData(data *ipListResponseCloudflare) erro
 		return err
 	}
 
+	return m.source().Update(func() (string, error) {
+		return signature, m.writeDataFiles(data, signature)
+	})
+}
+
+// writeDataFiles replaces both CIDR lists and records their signature.
+func (m *IpDataManagerCloudflare) writeDataFiles(data *ipListResponseCloudflare, signature string) error {
 	if err := writeCIDRLines(m.DataFilePathV4, data.Result.V4CIDRs); err != nil {
 		return err
 	}
@@ -97,6 +106,19 @@ func (m *IpDataManagerCloudflare) writeData(data *ipListResponseCloudflare) erro
 	return nil
 }
 
+// source describes the Cloudflare CIDR lists for loading and archiving their
+// ranges.
+func (m *IpDataManagerCloudflare) source() provider.DataSource[IpRangeDataCloudflare] {
+	return provider.DataSource[IpRangeDataCloudflare]{
+		Load:     m.LoadIpData,
+		Read:     m.readDataFiles,
+		Convert:  rangesFromData,
+		Files:    []string{m.DataFilePathV4, m.DataFilePathV6},
+		Metadata: metadataManager,
+		Archive:  m.Archive,
+	}
+}
+
 func (m *IpDataManagerCloudflare) SetUpdatePolicy(policy common.UpdatePolicy) {
 	m.UpdatePolicy = policy
 }
@@ -153,6 +175,16 @@ func (m *IpDataManagerCloudflare) LoadIpData() (*IpRangeDataCloudflare, error) {
 		return &m.IpRange, nil
 	}
 
+	data, err := m.readDataFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	m.IpRange = *data
+	return &m.IpRange, nil
+}
+
+func (m *IpDataManagerCloudflare) readDataFiles() (*IpRangeDataCloudflare, error) {
 	v4CIDRs, err := readCIDRLines(m.DataFilePathV4)
 	if err != nil {
 		return nil, err
@@ -162,13 +194,10 @@ func (m *IpDataManagerCloudflare) LoadIpData() (*IpRangeDataCloudflare, error) {
 		return nil, err
 	}
 
-	data := IpRangeDataCloudflare{
+	return &IpRangeDataCloudflare{
 		V4CIDRs: v4CIDRs,
 		V6CIDRs: v6CIDRs,
-	}
-
-	m.IpRange = data
-	return &m.IpRange, nil
+	}, nil
 }
 
 func readCIDRLines(path string) ([]string, error) {
@@ -226,4 +255,5 @@ var ipDataManagerCloudflare = &IpDataManagerCloudflare{
 	DataFilePathV4: DataFilePathCloudflareV4,
 	DataFilePathV6: DataFilePathCloudflareV6,
 	IpRange:        IpRangeDataCloudflare{},
+	Archive:        &common.DataArchive{Provider: common.Cloudflare, Dir: ArchiveDirectoryCloudflare},
 }
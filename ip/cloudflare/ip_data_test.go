@@ -260,3 +260,64 @@ func TestCloudflareEnsureDataFileNoUpdateRequiresExistingFiles(t *testing.T) {
 		t.Fatalf("request count = %d, want 0", requestCount)
 	}
 }
+
+func TestCloudflareUpdateArchivesPreviousAndNewData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"success": true,
+			"result": {
+				"etag": "new-etag",
+				"ipv4_cidrs": ["173.245.48.0/20", "103.21.244.0/22"],
+				"ipv6_cidrs": ["2400:cb00::/32"]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	oldMetadataManager := metadataManager
+	metadataManager = &common.MetadataManager{
+		MetadataFilePath: filepath.Join(dir, ".metadata.json"),
+		ProviderDir:      dir,
+		Metadata:         &common.CloudMetadata{},
+	}
+	t.Cleanup(func() {
+		metadataManager = oldMetadataManager
+	})
+	if err := metadataManager.Write(&common.CloudMetadata{Type: common.Cloudflare, Signature: "old-etag"}); err != nil {
+		t.Fatalf("Write(metadata) error = %v", err)
+	}
+
+	archive := &common.DataArchive{Provider: common.Cloudflare, Dir: filepath.Join(dir, ArchiveDir)}
+	manager := &IpDataManagerCloudflare{
+		DataURI:        server.URL,
+		DataFilePathV4: filepath.Join(dir, "cloudflare-v4.txt"),
+		DataFilePathV6: filepath.Join(dir, "cloudflare-v6.txt"),
+		Archive:        archive,
+	}
+	if err := writeCIDRLines(manager.DataFilePathV4, []string{"173.245.48.0/20"}); err != nil {
+		t.Fatalf("writeCIDRLines(v4) error = %v", err)
+	}
+	if err := writeCIDRLines(manager.DataFilePathV6, []string{"2400:cb00::/32"}); err != nil {
+		t.Fatalf("writeCIDRLines(v6) error = %v", err)
+	}
+
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+
+	versions, err := archive.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].Signature != "old-etag" || versions[1].Signature != "new-etag" {
+		t.Fatalf("versions = %+v, want old-etag and new-etag", versions)
+	}
+	if versions[0].Count != 2 || versions[1].Count != 3 {
+		t.Errorf("counts = %d, %d, want 2, 3", versions[0].Count, versions[1].Count)
+	}
+	if manager.IpRange.V4CIDRs != nil {
+		t.Errorf("archiving left data loaded: %+v", manager.IpRange)
+	}
+}
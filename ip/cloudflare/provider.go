@@ -1,39 +1,31 @@
 package cloudflare
 
 import (
+	"cloudip/common"
 	"cloudip/ip/provider"
-	"cloudip/util"
 )
 
 type CloudflareProvider struct {
-	*provider.BaseProvider
+	*provider.DataProvider[IpRangeDataCloudflare]
 }
 
 func NewCloudflareProvider() *CloudflareProvider {
 	return &CloudflareProvider{
-		BaseProvider: provider.NewBaseProvider("Cloudflare", ipDataManagerCloudflare, func(bp *provider.BaseProvider) error {
-			data, err := ipDataManagerCloudflare.LoadIpData()
-			if err != nil {
-				return err
-			}
-
-			for _, cidr := range data.V4CIDRs {
-				if err := bp.AddIPv4Range(cidr); err != nil {
-					util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+cidr))
-					continue
-				}
-			}
-
-			for _, cidr := range data.V6CIDRs {
-				if err := bp.AddIPv6Range(cidr); err != nil {
-					util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+cidr))
-					continue
-				}
-			}
+		DataProvider: provider.NewDataProvider("Cloudflare", ipDataManagerCloudflare, ipDataManagerCloudflare.source),
+	}
+}
 
-			return nil
-		}),
+// rangesFromData converts the Cloudflare CIDR lists into ranges. Cloudflare does
+// not publish regions or services for its prefixes.
+func rangesFromData(data *IpRangeDataCloudflare) []common.RangeInfo {
+	ranges := make([]common.RangeInfo, 0, len(data.V4CIDRs)+len(data.V6CIDRs))
+	for _, cidr := range data.V4CIDRs {
+		ranges = append(ranges, common.RangeInfo{Prefix: cidr})
+	}
+	for _, cidr := range data.V6CIDRs {
+		ranges = append(ranges, common.RangeInfo{Prefix: cidr})
 	}
+	return ranges
 }
 
 var Provider = NewCloudflareProvider()
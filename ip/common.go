@@ -7,6 +7,11 @@ import (
 var DefaultProviderOrder = []common.CloudProvider{
 	common.AWS,
 	common.GCP,
+	common.Google,
 	common.Azure,
 	common.Cloudflare,
+	common.OCI,
+	common.DigitalOcean,
+	common.Linode,
+	common.Vultr,
 }
@@ -0,0 +1,197 @@
+package custom
+
+import (
+	"bytes"
+	"cloudip/common"
+	"cloudip/util"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var appDir = util.GetAppDir(common.AppName)
+
+const ConfigFile = "providers.json"
+
+// ConfigEnv names the environment variable that overrides the config path.
+const ConfigEnv = "CLOUDIP_CONFIG"
+
+const (
+	FormatJSON = "json"
+	FormatCIDR = "cidr"
+	FormatCSV  = "csv"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedNames are provider values the output already uses for results
+// that belong to no provider.
+var reservedNames = []common.CloudProvider{"unknown", "error", common.Reserved}
+
+// Config lists the user-defined providers.
+type Config struct {
+	Providers []ProviderConfig `json:"providers"`
+}
+
+// ProviderConfig declares a provider whose ranges are read from a URL or a
+// local file.
+type ProviderConfig struct {
+	Name    string      `json:"name"`
+	Source  string      `json:"source,omitempty"`  // URL or local path, relative to the config file
+	Sources []string    `json:"sources,omitempty"` // Local paths read as one list, instead of source
+	Format  string      `json:"format"`            // json, cidr or csv
+	Refresh string      `json:"refresh,omitempty"` // Update check TTL of a URL source, such as "6h"
+	JSON    *JSONFormat `json:"json,omitempty"`
+	CSV     *CSVFormat  `json:"csv,omitempty"`
+}
+
+// JSONFormat locates the ranges in a JSON document. Paths are dot separated
+// object keys, and arrays met on the way are flattened, so "regions.cidrs"
+// reaches the entries of every region.
+type JSONFormat struct {
+	Items   string `json:"items,omitempty"`   // Path of the entries, the document itself when empty
+	Prefix  string `json:"prefix,omitempty"`  // Path of the prefix in an entry, the entry itself when empty
+	Region  string `json:"region,omitempty"`  // Path of the region in an entry
+	Service string `json:"service,omitempty"` // Path of the service in an entry
+}
+
+// CSVFormat locates the ranges in CSV rows. Columns are numbered from 1 and
+// a zero column is not read.
+type CSVFormat struct {
+	Prefix    int    `json:"prefix,omitempty"` // Column of the prefix, 1 when zero
+	Region    int    `json:"region,omitempty"`
+	Service   int    `json:"service,omitempty"`
+	Delimiter string `json:"delimiter,omitempty"` // Field delimiter, "," when empty
+	Header    bool   `json:"header,omitempty"`    // Skip the first row
+}
+
+// ConfigPath returns the path of the config file, which CLOUDIP_CONFIG
+// overrides.
+func ConfigPath() string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path
+	}
+	return filepath.Join(appDir, ConfigFile)
+}
+
+// LoadConfig reads the config file. A missing file is an empty config.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading config file")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	config := &Config{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	for i := range config.Providers {
+		providerConfig := &config.Providers[i]
+		providerConfig.Source = resolvePath(path, providerConfig.Source)
+		for j, source := range providerConfig.Sources {
+			providerConfig.Sources[j] = resolvePath(path, source)
+		}
+	}
+	return config, nil
+}
+
+// Validate checks the provider declarations. Names must be unique and must
+// not shadow a built-in provider.
+func (config *Config) Validate(builtin []common.CloudProvider) error {
+	taken := make(map[common.CloudProvider]bool)
+	for _, name := range builtin {
+		taken[name] = true
+	}
+	for _, name := range reservedNames {
+		taken[name] = true
+	}
+
+	for _, providerConfig := range config.Providers {
+		if err := providerConfig.validate(); err != nil {
+			return err
+		}
+		providerType := common.CloudProvider(providerConfig.Name)
+		if taken[providerType] {
+			return fmt.Errorf("provider %s is already defined", providerConfig.Name)
+		}
+		taken[providerType] = true
+	}
+	return nil
+}
+
+func (providerConfig ProviderConfig) validate() error {
+	if !namePattern.MatchString(providerConfig.Name) {
+		return fmt.Errorf("invalid provider name: %q. Use lowercase letters, digits, '-' and '_'", providerConfig.Name)
+	}
+	if providerConfig.Source == "" && len(providerConfig.Sources) == 0 {
+		return fmt.Errorf("provider %s has no source", providerConfig.Name)
+	}
+	if providerConfig.Source != "" && len(providerConfig.Sources) > 0 {
+		return fmt.Errorf("provider %s has both source and sources", providerConfig.Name)
+	}
+	for _, source := range providerConfig.Sources {
+		if source == "" || isURL(source) {
+			return fmt.Errorf("invalid source for provider %s: %q. sources only lists local files", providerConfig.Name, source)
+		}
+	}
+	switch providerConfig.Format {
+	case FormatJSON, FormatCIDR, FormatCSV:
+	default:
+		return fmt.Errorf("invalid format for provider %s: %s. Supported formats are: %s, %s, %s",
+			providerConfig.Name, providerConfig.Format, FormatJSON, FormatCIDR, FormatCSV)
+	}
+	if _, err := providerConfig.refreshTTL(); err != nil {
+		return err
+	}
+	if csvFormat := providerConfig.CSV; csvFormat != nil {
+		if csvFormat.Prefix < 0 || csvFormat.Region < 0 || csvFormat.Service < 0 {
+			return fmt.Errorf("invalid CSV column for provider %s: columns start at 1", providerConfig.Name)
+		}
+		if len([]rune(csvFormat.Delimiter)) > 1 {
+			return fmt.Errorf("invalid CSV delimiter for provider %s: %q", providerConfig.Name, csvFormat.Delimiter)
+		}
+	}
+	return nil
+}
+
+// refreshTTL returns the update check TTL, zero when the default applies.
+func (providerConfig ProviderConfig) refreshTTL() (time.Duration, error) {
+	if providerConfig.Refresh == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(providerConfig.Refresh)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid refresh for provider %s: %s. Use a duration such as 6h", providerConfig.Name, providerConfig.Refresh)
+	}
+	return ttl, nil
+}
+
+// localPaths returns the files of a provider with local sources.
+func (providerConfig ProviderConfig) localPaths() []string {
+	if len(providerConfig.Sources) > 0 {
+		return providerConfig.Sources
+	}
+	return []string{providerConfig.Source}
+}
+
+// resolvePath makes a local source relative to the config file absolute.
+func resolvePath(configPath, source string) string {
+	if source == "" || isURL(source) || filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(filepath.Dir(configPath), source)
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
@@ -0,0 +1,111 @@
+package custom
+
+import (
+	"cloudip/common"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigResolvesLocalSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFile)
+	if err := os.WriteFile(path, []byte(`{
+		"providers": [
+			{"name": "office", "source": "office.txt", "format": "cidr"},
+			{"name": "corp", "sources": ["vpn.txt", "/etc/cloudip/egress.txt"], "format": "cidr"},
+			{"name": "partner", "source": "https://example.com/ranges.json", "format": "json", "refresh": "6h"}
+		]
+	}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.Providers) != 3 {
+		t.Fatalf("len(Providers) = %d, want 3", len(config.Providers))
+	}
+	if got := config.Providers[0].Source; got != filepath.Join(dir, "office.txt") {
+		t.Errorf("local source = %q, want it relative to the config file", got)
+	}
+	if got := config.Providers[1].Sources; len(got) != 2 || got[0] != filepath.Join(dir, "vpn.txt") || got[1] != "/etc/cloudip/egress.txt" {
+		t.Errorf("sources = %q, want relative paths resolved and absolute paths unchanged", got)
+	}
+	if got := config.Providers[2].Source; got != "https://example.com/ranges.json" {
+		t.Errorf("URL source = %q, want it unchanged", got)
+	}
+	if ttl, err := config.Providers[2].refreshTTL(); err != nil || ttl != 6*time.Hour {
+		t.Errorf("refreshTTL() = %v, %v, want 6h", ttl, err)
+	}
+}
+
+func TestLoadConfigMissingFileIsEmpty(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFile))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.Providers) != 0 {
+		t.Fatalf("Providers = %+v, want none", config.Providers)
+	}
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(path, []byte(`{"providers": [{"name": "office", "sources": "office.txt"}]}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "sources") {
+		t.Fatalf("LoadConfig() error = %v, want unknown field error", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	builtin := []common.CloudProvider{common.AWS, common.GCP}
+	tests := []struct {
+		name     string
+		provider ProviderConfig
+		wantErr  string
+	}{
+		{"valid", ProviderConfig{Name: "corp-vpn", Source: "vpn.txt", Format: FormatCIDR}, ""},
+		{"uppercase name", ProviderConfig{Name: "Corp", Source: "vpn.txt", Format: FormatCIDR}, "invalid provider name"},
+		{"selection syntax in name", ProviderConfig{Name: "corp:vpn", Source: "vpn.txt", Format: FormatCIDR}, "invalid provider name"},
+		{"built-in name", ProviderConfig{Name: "aws", Source: "aws.txt", Format: FormatCIDR}, "already defined"},
+		{"result name", ProviderConfig{Name: "unknown", Source: "unknown.txt", Format: FormatCIDR}, "already defined"},
+		{"no source", ProviderConfig{Name: "corp", Format: FormatCIDR}, "has no source"},
+		{"several local sources", ProviderConfig{Name: "corp", Sources: []string{"vpn.txt", "office.txt"}, Format: FormatCIDR}, ""},
+		{"source and sources", ProviderConfig{Name: "corp", Source: "a.txt", Sources: []string{"b.txt"}, Format: FormatCIDR}, "both source and sources"},
+		{"URL in sources", ProviderConfig{Name: "corp", Sources: []string{"https://example.com/a.txt"}, Format: FormatCIDR}, "only lists local files"},
+		{"unknown format", ProviderConfig{Name: "corp", Source: "corp.yaml", Format: "yaml"}, "invalid format"},
+		{"invalid refresh", ProviderConfig{Name: "corp", Source: "corp.txt", Format: FormatCIDR, Refresh: "daily"}, "invalid refresh"},
+		{"negative column", ProviderConfig{Name: "corp", Source: "corp.csv", Format: FormatCSV, CSV: &CSVFormat{Prefix: -1}}, "invalid CSV column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Providers: []ProviderConfig{tt.provider}}
+			err := config.Validate(builtin)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	duplicate := &Config{Providers: []ProviderConfig{
+		{Name: "corp", Source: "a.txt", Format: FormatCIDR},
+		{Name: "corp", Source: "b.txt", Format: FormatCIDR},
+	}}
+	if err := duplicate.Validate(builtin); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("Validate() error = %v, want duplicate name error", err)
+	}
+}
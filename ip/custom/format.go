@@ -0,0 +1,201 @@
+package custom
+
+import (
+	"bufio"
+	"bytes"
+	"cloudip/common"
+	"cloudip/util"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// parseRanges reads the ranges of a source in the format of the provider.
+// An entry without a valid prefix is an error, so a wrong path or column is
+// reported instead of silently matching nothing.
+func parseRanges(providerConfig ProviderConfig, data []byte) ([]common.RangeInfo, error) {
+	switch providerConfig.Format {
+	case FormatJSON:
+		jsonFormat := JSONFormat{}
+		if providerConfig.JSON != nil {
+			jsonFormat = *providerConfig.JSON
+		}
+		return parseJSONRanges(jsonFormat, data)
+	case FormatCSV:
+		csvFormat := CSVFormat{}
+		if providerConfig.CSV != nil {
+			csvFormat = *providerConfig.CSV
+		}
+		return parseCSVRanges(csvFormat, data)
+	case FormatCIDR:
+		return parseCIDRRanges(data)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", providerConfig.Format)
+}
+
+func parseJSONRanges(format JSONFormat, data []byte) ([]common.RangeInfo, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, util.ErrorWithInfo(err, "error decoding JSON data")
+	}
+
+	var ranges []common.RangeInfo
+	for i, item := range lookupPath(document, format.Items) {
+		prefixes := lookupPath(item, format.Prefix)
+		if len(prefixes) == 0 {
+			return nil, fmt.Errorf("entry %d has no prefix at %q", i+1, format.Prefix)
+		}
+		for _, value := range prefixes {
+			prefix, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("entry %d: prefix is not a string: %v", i+1, value)
+			}
+			info, err := newRangeInfo(prefix, lookupString(item, format.Region), lookupString(item, format.Service))
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			ranges = append(ranges, info)
+		}
+	}
+	return ranges, nil
+}
+
+// lookupPath returns the values at a dot separated path. Arrays are flattened
+// on the way and at the end, and missing keys yield no values.
+func lookupPath(value any, path string) []any {
+	values := flatten([]any{value})
+	if path == "" {
+		return values
+	}
+	for _, key := range strings.Split(path, ".") {
+		var next []any
+		for _, value := range values {
+			object, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			if child, exists := object[key]; exists {
+				next = append(next, child)
+			}
+		}
+		values = flatten(next)
+	}
+	return values
+}
+
+func flatten(values []any) []any {
+	var flat []any
+	for _, value := range values {
+		if array, ok := value.([]any); ok {
+			flat = append(flat, flatten(array)...)
+			continue
+		}
+		flat = append(flat, value)
+	}
+	return flat
+}
+
+// lookupString returns the first string at the path, or an empty string.
+func lookupString(value any, path string) string {
+	if path == "" {
+		return ""
+	}
+	for _, found := range lookupPath(value, path) {
+		if s, ok := found.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func parseCSVRanges(format CSVFormat, data []byte) ([]common.RangeInfo, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if format.Delimiter != "" {
+		reader.Comma = []rune(format.Delimiter)[0]
+	}
+	prefixColumn := format.Prefix
+	if prefixColumn == 0 {
+		prefixColumn = 1
+	}
+
+	var ranges []common.RangeInfo
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, util.ErrorWithInfo(err, "error reading CSV data")
+		}
+		if row == 1 && format.Header {
+			continue
+		}
+
+		column := func(index int) string {
+			if index < 1 || index > len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index-1])
+		}
+		info, err := newRangeInfo(column(prefixColumn), column(format.Region), column(format.Service))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		ranges = append(ranges, info)
+	}
+	return ranges, nil
+}
+
+// parseCIDRRanges reads one prefix per line, optionally followed by a label
+// after whitespace or a comma, as in "10.20.0.0/16 office-seoul". The label
+// becomes the service of the range. Blank lines and text after "#" are
+// skipped.
+func parseCIDRRanges(data []byte) ([]common.RangeInfo, error) {
+	var ranges []common.RangeInfo
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		prefix, label := text, ""
+		if i := strings.IndexAny(text, " \t,"); i >= 0 {
+			prefix = text[:i]
+			label = strings.TrimSpace(strings.TrimLeft(text[i:], " \t,"))
+		}
+		info, err := newRangeInfo(prefix, "", label)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if label != "" {
+			info.Attributes = map[string]string{"label": label}
+		}
+		ranges = append(ranges, info)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading CIDR list")
+	}
+	return ranges, nil
+}
+
+// newRangeInfo accepts a prefix or a single address, which becomes a host
+// prefix.
+func newRangeInfo(prefix, region, service string) (common.RangeInfo, error) {
+	prefix = strings.TrimSpace(prefix)
+	if addr, err := netip.ParseAddr(prefix); err == nil {
+		prefix = netip.PrefixFrom(addr, addr.BitLen()).String()
+	}
+	parsed, err := util.ParsePrefix(prefix)
+	if err != nil {
+		return common.RangeInfo{}, fmt.Errorf("invalid prefix: %q", prefix)
+	}
+	return common.RangeInfo{Prefix: parsed.String(), Region: region, Service: service}, nil
+}
@@ -0,0 +1,110 @@
+package custom
+
+import (
+	"cloudip/common"
+	"strings"
+	"testing"
+)
+
+func assertRanges(t *testing.T, got, want []common.RangeInfo) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("ranges = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Prefix != want[i].Prefix || got[i].Region != want[i].Region || got[i].Service != want[i].Service {
+			t.Errorf("ranges[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseJSONRangesFollowsPaths(t *testing.T) {
+	providerConfig := ProviderConfig{Format: FormatJSON, JSON: &JSONFormat{
+		Items:   "regions.entries",
+		Prefix:  "cidrs",
+		Region:  "location.name",
+		Service: "service",
+	}}
+	ranges, err := parseRanges(providerConfig, []byte(`{
+		"regions": [
+			{"entries": [{"cidrs": ["192.0.2.0/25", "2001:db8::/48"], "location": {"name": "eu-west"}, "service": "api"}]},
+			{"entries": [{"cidrs": "198.51.100.0/24", "service": "web"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("parseRanges() error = %v", err)
+	}
+
+	assertRanges(t, ranges, []common.RangeInfo{
+		{Prefix: "192.0.2.0/25", Region: "eu-west", Service: "api"},
+		{Prefix: "2001:db8::/48", Region: "eu-west", Service: "api"},
+		{Prefix: "198.51.100.0/24", Service: "web"},
+	})
+}
+
+func TestParseJSONRangesTopLevelArray(t *testing.T) {
+	ranges, err := parseRanges(ProviderConfig{Format: FormatJSON}, []byte(`["192.0.2.0/24", "198.51.100.1"]`))
+	if err != nil {
+		t.Fatalf("parseRanges() error = %v", err)
+	}
+
+	assertRanges(t, ranges, []common.RangeInfo{{Prefix: "192.0.2.0/24"}, {Prefix: "198.51.100.1/32"}})
+}
+
+func TestParseJSONRangesReportsMissingPrefix(t *testing.T) {
+	providerConfig := ProviderConfig{Format: FormatJSON, JSON: &JSONFormat{Items: "prefixes", Prefix: "ip_prefix"}}
+	_, err := parseRanges(providerConfig, []byte(`{"prefixes": [{"ipv4Prefix": "192.0.2.0/24"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "entry 1 has no prefix") {
+		t.Fatalf("parseRanges() error = %v, want missing prefix error", err)
+	}
+}
+
+func TestParseCSVRangesReadsColumns(t *testing.T) {
+	providerConfig := ProviderConfig{Format: FormatCSV, CSV: &CSVFormat{Prefix: 2, Region: 3, Service: 1, Delimiter: ";", Header: true}}
+	ranges, err := parseRanges(providerConfig, []byte("service;prefix;region\n# comment\nvpn; 192.0.2.0/24 ;seoul\nmail;2001:db8::/32\n"))
+	if err != nil {
+		t.Fatalf("parseRanges() error = %v", err)
+	}
+
+	assertRanges(t, ranges, []common.RangeInfo{
+		{Prefix: "192.0.2.0/24", Region: "seoul", Service: "vpn"},
+		{Prefix: "2001:db8::/32", Service: "mail"},
+	})
+}
+
+func TestParseCSVRangesReportsInvalidPrefix(t *testing.T) {
+	_, err := parseRanges(ProviderConfig{Format: FormatCSV}, []byte("192.0.2.0/24\nexample.com\n"))
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("parseRanges() error = %v, want row 2 error", err)
+	}
+}
+
+func TestParseCIDRRangesSkipsCommentsAndMasksPrefixes(t *testing.T) {
+	ranges, err := parseRanges(ProviderConfig{Format: FormatCIDR}, []byte("# egress\n\n192.0.2.10/24\n  2001:db8::1\n"))
+	if err != nil {
+		t.Fatalf("parseRanges() error = %v", err)
+	}
+
+	assertRanges(t, ranges, []common.RangeInfo{{Prefix: "192.0.2.0/24"}, {Prefix: "2001:db8::1/128"}})
+}
+
+func TestParseCIDRRangesReadsLabels(t *testing.T) {
+	ranges, err := parseRanges(ProviderConfig{Format: FormatCIDR}, []byte(
+		"10.20.0.0/16 office-seoul\n203.0.113.7, vpn egress # primary\n2001:db8::/48\toffice-tokyo\n198.51.100.0/24 #unlabelled\n"))
+	if err != nil {
+		t.Fatalf("parseRanges() error = %v", err)
+	}
+
+	assertRanges(t, ranges, []common.RangeInfo{
+		{Prefix: "10.20.0.0/16", Service: "office-seoul"},
+		{Prefix: "203.0.113.7/32", Service: "vpn egress"},
+		{Prefix: "2001:db8::/48", Service: "office-tokyo"},
+		{Prefix: "198.51.100.0/24"},
+	})
+	if ranges[1].Attributes["label"] != "vpn egress" {
+		t.Errorf("label = %q, want vpn egress", ranges[1].Attributes["label"])
+	}
+	if ranges[3].Attributes != nil {
+		t.Errorf("attributes = %v, want none without a label", ranges[3].Attributes)
+	}
+}
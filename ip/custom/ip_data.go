@@ -0,0 +1,247 @@
+package custom
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"fmt"
+	"os"
+	"time"
+)
+
+// IpDataManagerCustom keeps the data of a user-defined provider. A URL source
+// is downloaded into DataFilePath and checked for updates like the built-in
+// providers; local sources are read in place and never touch the network.
+type IpDataManagerCustom struct {
+	Config          ProviderConfig
+	DataFilePath    string
+	IpRange         IpRangeDataCustom
+	UpdatePolicy    common.UpdatePolicy
+	MetadataManager *common.MetadataManager
+	Archive         *common.DataArchive
+}
+
+type IpRangeDataCustom struct {
+	Ranges []common.RangeInfo
+}
+
+func (ipRange IpRangeDataCustom) IsEmpty() bool {
+	return len(ipRange.Ranges) == 0
+}
+
+func (m *IpDataManagerCustom) isLocal() bool {
+	return !isURL(m.Config.Source)
+}
+
+func (m *IpDataManagerCustom) downloadData() error {
+	common.VerboseOutput(fmt.Sprintf("Downloading %s IP ranges...", m.Config.Name))
+	data, err := m.fetchData()
+	if err != nil {
+		return err
+	}
+	return m.writeData(data)
+}
+
+// fetchData downloads the source and checks that it parses, so a changed
+// upstream layout does not replace the data file.
+func (m *IpDataManagerCustom) fetchData() ([]byte, error) {
+	data, _, err := util.DownloadFromUrl(m.Config.Source)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (m *IpDataManagerCustom) checkData(data []byte) error {
+	ranges, err := parseRanges(m.Config, data)
+	if err != nil {
+		return fmt.Errorf("error parsing %s ranges: %w", m.Config.Name, err)
+	}
+	if len(ranges) == 0 {
+		return fmt.Errorf("%s source has no ranges", m.Config.Name)
+	}
+	return nil
+}
+
+func (m *IpDataManagerCustom) writeData(data []byte) error {
+	signature := util.ContentSignature(data)
+
+	return m.source().Update(func() (string, error) {
+		if err := os.WriteFile(m.DataFilePath, data, 0644); err != nil {
+			err = util.ErrorWithInfo(err, "error writing data file")
+			util.PrintErrorTrace(err)
+			return "", err
+		}
+		return signature, m.writeMetadata(signature)
+	})
+}
+
+func (m *IpDataManagerCustom) writeMetadata(signature string) error {
+	signatureExpired := m.MetadataManager.IsSignatureExpired(signature)
+	metadata := common.CloudMetadata{
+		Type:        m.MetadataManager.Metadata.Type,
+		Signature:   signature,
+		LastChecked: time.Now().Unix(),
+	}
+	if err := m.MetadataManager.Write(&metadata); err != nil {
+		err = util.ErrorWithInfo(err, "error writing metadata")
+		util.PrintErrorTrace(err)
+		return err
+	}
+	if signatureExpired {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges updated [%s]", m.Config.Name, signature))
+	}
+
+	return nil
+}
+
+// source describes the provider data for loading and archiving its ranges:
+// the downloaded file of a URL source, or the local sources themselves.
+func (m *IpDataManagerCustom) source() provider.DataSource[IpRangeDataCustom] {
+	files := []string{m.DataFilePath}
+	if m.isLocal() {
+		files = m.Config.localPaths()
+	}
+	return provider.DataSource[IpRangeDataCustom]{
+		Load:     m.LoadIpData,
+		Read:     m.readDataFile,
+		Convert:  rangesFromData,
+		Files:    files,
+		Metadata: m.MetadataManager,
+		Archive:  m.Archive,
+	}
+}
+
+// SetUpdatePolicy applies the policy, with the refresh of the config as TTL
+// when it has one.
+func (m *IpDataManagerCustom) SetUpdatePolicy(policy common.UpdatePolicy) {
+	if ttl, err := m.Config.refreshTTL(); err == nil && ttl > 0 {
+		policy.TTL = ttl
+	}
+	m.UpdatePolicy = policy
+}
+
+func (m *IpDataManagerCustom) EnsureDataFile() error {
+	if err := m.MetadataManager.Ensure(); err != nil {
+		return err
+	}
+	if err := m.MetadataManager.Read(); err != nil {
+		return err
+	}
+	if m.isLocal() {
+		return m.ensureLocalFile()
+	}
+
+	if !util.IsFileExists(m.DataFilePath) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges file does not exist.", m.Config.Name))
+		if m.UpdatePolicy.NoUpdate {
+			return fmt.Errorf("%s IP ranges file does not exist and --no-update is enabled", m.Config.Name)
+		}
+		return m.downloadData()
+	}
+
+	policy := m.UpdatePolicy
+	if policy.NoUpdate {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped.", m.Config.Name))
+		return nil
+	}
+	if m.MetadataManager.IsUpdateCheckFresh(time.Now(), policy.EffectiveTTL()) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped; cache is fresh.", m.Config.Name))
+		return nil
+	}
+
+	data, err := m.fetchData()
+	if err != nil {
+		util.PrintErrorTrace(util.ErrorWithInfo(err, fmt.Sprintf("error getting %s IP ranges", m.Config.Name)))
+		return nil
+	}
+	if m.MetadataManager.IsSignatureExpired(util.ContentSignature(data)) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges are outdated. Updating to the latest version...", m.Config.Name))
+		return m.writeData(data)
+	}
+	if err := m.MetadataManager.MarkChecked(time.Now()); err != nil {
+		return util.ErrorWithInfo(err, "error writing metadata")
+	}
+	common.VerboseOutput(fmt.Sprintf("%s IP ranges are up-to-date.", m.Config.Name))
+
+	return nil
+}
+
+// ensureLocalFile records the signature of the local sources. The files are
+// edited in place, so only the new version can be archived when they change.
+func (m *IpDataManagerCustom) ensureLocalFile() error {
+	ranges, signature, err := m.readLocalFiles()
+	if err != nil {
+		return err
+	}
+	if len(ranges) == 0 {
+		return fmt.Errorf("%s source has no ranges", m.Config.Name)
+	}
+	if !m.MetadataManager.IsSignatureExpired(signature) {
+		return nil
+	}
+	if err := m.writeMetadata(signature); err != nil {
+		return err
+	}
+	m.source().KeepCurrent()
+	return nil
+}
+
+// readLocalFiles parses every local source, in the order they are listed,
+// and signs their combined content.
+func (m *IpDataManagerCustom) readLocalFiles() ([]common.RangeInfo, string, error) {
+	var ranges []common.RangeInfo
+	var content []byte
+	for _, path := range m.Config.localPaths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", util.ErrorWithInfo(err, fmt.Sprintf("error reading %s ranges file", m.Config.Name))
+		}
+		fileRanges, err := parseRanges(m.Config, data)
+		if err != nil {
+			return nil, "", fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		ranges = append(ranges, fileRanges...)
+		content = append(append(content, path...), 0)
+		content = append(append(content, data...), 0)
+	}
+	return ranges, util.ContentSignature(content), nil
+}
+
+func (m *IpDataManagerCustom) LoadIpData() (*IpRangeDataCustom, error) {
+	if !m.IpRange.IsEmpty() {
+		return &m.IpRange, nil
+	}
+
+	data, err := m.readDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	m.IpRange = *data
+	return &m.IpRange, nil
+}
+
+func (m *IpDataManagerCustom) readDataFile() (*IpRangeDataCustom, error) {
+	var ranges []common.RangeInfo
+	if m.isLocal() {
+		localRanges, _, err := m.readLocalFiles()
+		if err != nil {
+			return nil, err
+		}
+		ranges = localRanges
+	} else {
+		data, err := os.ReadFile(m.DataFilePath)
+		if err != nil {
+			return nil, util.ErrorWithInfo(err, "error opening data file")
+		}
+		if ranges, err = parseRanges(m.Config, data); err != nil {
+			return nil, util.ErrorWithInfo(err, "error reading data file")
+		}
+	}
+
+	return &IpRangeDataCustom{Ranges: ranges}, nil
+}
@@ -0,0 +1,167 @@
+package custom
+
+import (
+	"cloudip/common"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T, providerConfig ProviderConfig, dataFilePath string) *IpDataManagerCustom {
+	dir := t.TempDir()
+	return &IpDataManagerCustom{
+		Config:       providerConfig,
+		DataFilePath: dataFilePath,
+		MetadataManager: &common.MetadataManager{
+			MetadataFilePath: filepath.Join(dir, ".metadata.json"),
+			ProviderDir:      dir,
+			Metadata: &common.CloudMetadata{
+				Type: common.CloudProvider(providerConfig.Name),
+			},
+		},
+		Archive: &common.DataArchive{Provider: common.CloudProvider(providerConfig.Name), Dir: filepath.Join(dir, "archive")},
+	}
+}
+
+func TestCustomLocalSourceRecordsSignatureOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "office.txt")
+	if err := os.WriteFile(path, []byte("192.0.2.0/24\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	manager := newTestManager(t, ProviderConfig{Name: "office", Source: path, Format: FormatCIDR}, path)
+
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	firstSignature := manager.MetadataManager.Metadata.Signature
+	if firstSignature == "" {
+		t.Fatal("metadata signature is empty")
+	}
+
+	if err := os.WriteFile(path, []byte("192.0.2.0/24\n198.51.100.0/24\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if manager.MetadataManager.Metadata.Signature == firstSignature {
+		t.Fatal("metadata signature unchanged after the file changed")
+	}
+
+	versions, err := manager.Archive.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[1].Count != 2 {
+		t.Fatalf("versions = %+v, want two versions with 2 ranges last", versions)
+	}
+}
+
+func TestCustomLocalSourceReportsMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	manager := newTestManager(t, ProviderConfig{Name: "office", Source: path, Format: FormatCIDR}, path)
+
+	err := manager.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), "office") {
+		t.Fatalf("EnsureDataFile() error = %v, want missing file error", err)
+	}
+}
+
+func TestCustomURLSourceUsesRefreshTTL(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		_, _ = w.Write([]byte(`{"prefixes": [{"prefix": "192.0.2.0/24", "region": "eu"}]}`))
+	}))
+	defer server.Close()
+
+	providerConfig := ProviderConfig{
+		Name:    "partner",
+		Source:  server.URL,
+		Format:  FormatJSON,
+		Refresh: "1h",
+		JSON:    &JSONFormat{Items: "prefixes", Prefix: "prefix", Region: "region"},
+	}
+	manager := newTestManager(t, providerConfig, filepath.Join(t.TempDir(), "partner.json"))
+	manager.SetUpdatePolicy(common.DefaultUpdatePolicy())
+	if manager.UpdatePolicy.TTL != time.Hour {
+		t.Fatalf("TTL = %v, want the configured refresh", manager.UpdatePolicy.TTL)
+	}
+
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if err := manager.MetadataManager.MarkChecked(time.Now().Add(-2 * time.Hour)); err != nil {
+		t.Fatalf("MarkChecked() error = %v", err)
+	}
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if requestCount != 2 {
+		t.Fatalf("request count = %d, want a download and an update check", requestCount)
+	}
+
+	data, err := manager.LoadIpData()
+	if err != nil {
+		t.Fatalf("LoadIpData() error = %v", err)
+	}
+	if len(data.Ranges) != 1 || data.Ranges[0].Prefix != "192.0.2.0/24" || data.Ranges[0].Region != "eu" {
+		t.Fatalf("Ranges = %+v, want 192.0.2.0/24 in eu", data.Ranges)
+	}
+}
+
+func TestCustomURLSourceRejectsUnparsableData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items": []}`))
+	}))
+	defer server.Close()
+
+	providerConfig := ProviderConfig{Name: "partner", Source: server.URL, Format: FormatJSON, JSON: &JSONFormat{Items: "prefixes"}}
+	manager := newTestManager(t, providerConfig, filepath.Join(t.TempDir(), "partner.json"))
+
+	err := manager.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), "has no ranges") {
+		t.Fatalf("EnsureDataFile() error = %v, want no ranges error", err)
+	}
+	if _, statErr := os.Stat(manager.DataFilePath); !os.IsNotExist(statErr) {
+		t.Fatalf("data file exists after rejected download: %v", statErr)
+	}
+}
+
+func TestCustomLocalSourcesAreReadTogether(t *testing.T) {
+	dir := t.TempDir()
+	vpnPath := filepath.Join(dir, "vpn.txt")
+	officePath := filepath.Join(dir, "office.txt")
+	if err := os.WriteFile(vpnPath, []byte("203.0.113.7 vpn-egress\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(officePath, []byte("10.20.0.0/16 office-seoul\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	providerConfig := ProviderConfig{Name: "corp", Sources: []string{vpnPath, officePath}, Format: FormatCIDR}
+	manager := newTestManager(t, providerConfig, "")
+	manager.SetUpdatePolicy(common.UpdatePolicy{NoUpdate: true})
+
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	data, err := manager.LoadIpData()
+	if err != nil {
+		t.Fatalf("LoadIpData() error = %v", err)
+	}
+	if len(data.Ranges) != 2 || data.Ranges[0].Service != "vpn-egress" || data.Ranges[1].Service != "office-seoul" {
+		t.Fatalf("Ranges = %+v, want the ranges of both files in order", data.Ranges)
+	}
+
+	if err := os.WriteFile(officePath, []byte("10.20.0.0/16 office-seoul\nnot-a-prefix\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err = manager.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), officePath) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("EnsureDataFile() error = %v, want the file and line of the invalid prefix", err)
+	}
+}
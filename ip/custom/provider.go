@@ -0,0 +1,65 @@
+package custom
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"fmt"
+)
+
+// CustomProvider is a provider declared in the config file. Every provider
+// keeps its data, metadata and archive in its own directory.
+type CustomProvider struct {
+	*provider.DataProvider[IpRangeDataCustom]
+	Type common.CloudProvider
+}
+
+func NewCustomProvider(providerConfig ProviderConfig) *CustomProvider {
+	providerType := common.CloudProvider(providerConfig.Name)
+	directory := fmt.Sprintf("%s/%s", appDir, providerType)
+	dataFilePath := ""
+	if isURL(providerConfig.Source) {
+		dataFilePath = fmt.Sprintf("%s/%s.%s", directory, providerType, providerConfig.Format)
+	}
+
+	ipDataManager := &IpDataManagerCustom{
+		Config:       providerConfig,
+		DataFilePath: dataFilePath, // Empty for local sources
+		MetadataManager: &common.MetadataManager{
+			MetadataFilePath: fmt.Sprintf("%s/%s", directory, ".metadata.json"),
+			ProviderDir:      directory,
+			Metadata: &common.CloudMetadata{
+				Type:      providerType,
+				Signature: "",
+			},
+		},
+		Archive: &common.DataArchive{Provider: providerType, Dir: fmt.Sprintf("%s/%s", directory, "archive")},
+	}
+
+	return &CustomProvider{
+		DataProvider: provider.NewDataProvider(providerConfig.Name, ipDataManager, ipDataManager.source),
+		Type:         providerType,
+	}
+}
+
+// LoadProviders returns the providers declared in the config file, in the
+// order they are declared. Names must not shadow the built-in providers.
+func LoadProviders(path string, builtin []common.CloudProvider) ([]*CustomProvider, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(builtin); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	providers := make([]*CustomProvider, 0, len(config.Providers))
+	for _, providerConfig := range config.Providers {
+		providers = append(providers, NewCustomProvider(providerConfig))
+	}
+	return providers, nil
+}
+
+// rangesFromData returns the ranges parsed from the provider sources.
+func rangesFromData(data *IpRangeDataCustom) []common.RangeInfo {
+	return data.Ranges
+}
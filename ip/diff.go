@@ -0,0 +1,89 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// RangeChange is a range published in only one of two data versions.
+type RangeChange struct {
+	Added bool
+	Range common.RangeInfo
+}
+
+// rangeKey identifies a range across data versions.
+type rangeKey struct {
+	prefix  netip.Prefix
+	region  string
+	service string
+}
+
+// rangeKeyOf returns the key of a range whose prefix is known to parse.
+func rangeKeyOf(info common.RangeInfo) rangeKey {
+	prefix, _ := util.ParsePrefix(info.Prefix)
+	return rangeKey{prefix: prefix, region: strings.ToLower(info.Region), service: strings.ToLower(info.Service)}
+}
+
+// DiffRanges returns the ranges removed and added between two data versions
+// in address order. A range is identified by its prefix, region and service,
+// so a prefix that moves to another region or service is reported as removed
+// from the old one and added to the new one. Unparsable prefixes are skipped.
+func DiffRanges(from, to []common.RangeInfo) []RangeChange {
+	fromRanges := indexRanges(from)
+	toRanges := indexRanges(to)
+
+	type keyedChange struct {
+		key    rangeKey
+		change RangeChange
+	}
+	var changes []keyedChange
+	for key, info := range fromRanges {
+		if _, exists := toRanges[key]; !exists {
+			changes = append(changes, keyedChange{key, RangeChange{Added: false, Range: info}})
+		}
+	}
+	for key, info := range toRanges {
+		if _, exists := fromRanges[key]; !exists {
+			changes = append(changes, keyedChange{key, RangeChange{Added: true, Range: info}})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if cmp := util.ComparePrefixes(a.key.prefix, b.key.prefix); cmp != 0 {
+			return cmp < 0
+		}
+		if a.change.Added != b.change.Added {
+			return !a.change.Added
+		}
+		if a.key.region != b.key.region {
+			return a.key.region < b.key.region
+		}
+		return a.key.service < b.key.service
+	})
+
+	result := make([]RangeChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, change.change)
+	}
+	return result
+}
+
+// indexRanges maps every range to its key. Region and service are compared
+// case-insensitively, like the filters of the ranges command.
+func indexRanges(ranges []common.RangeInfo) map[rangeKey]common.RangeInfo {
+	index := make(map[rangeKey]common.RangeInfo, len(ranges))
+	for _, info := range ranges {
+		if _, err := util.ParsePrefix(info.Prefix); err != nil {
+			continue
+		}
+		key := rangeKeyOf(info)
+		if _, exists := index[key]; !exists {
+			index[key] = info
+		}
+	}
+	return index
+}
@@ -0,0 +1,45 @@
+package ip
+
+import (
+	"cloudip/common"
+	"testing"
+)
+
+func TestDiffRanges(t *testing.T) {
+	from := []common.RangeInfo{
+		{Prefix: "54.230.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"},
+		{Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"},
+		{Prefix: "13.32.0.0/15", Region: "GLOBAL", Service: "CLOUDFRONT"},
+	}
+	to := []common.RangeInfo{
+		{Prefix: "2600:9000::/28", Region: "GLOBAL", Service: "CLOUDFRONT"},
+		{Prefix: "54.230.0.0/16", Region: "global", Service: "CLOUDFRONT"},
+		{Prefix: "3.5.0.0/19", Region: "us-east-2", Service: "S3"},
+		{Prefix: "3.5.0.0/19", Region: "us-east-2", Service: "S3"},
+		{Prefix: "invalid", Region: "us-east-1"},
+	}
+
+	got := DiffRanges(from, to)
+	want := []struct {
+		added  bool
+		prefix string
+		region string
+	}{
+		{false, "3.5.0.0/19", "us-east-1"},
+		{true, "3.5.0.0/19", "us-east-2"},
+		{false, "13.32.0.0/15", "GLOBAL"},
+		{true, "2600:9000::/28", "GLOBAL"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DiffRanges() = %+v, want %d changes", got, len(want))
+	}
+	for i, change := range want {
+		if got[i].Added != change.added || got[i].Range.Prefix != change.prefix || got[i].Range.Region != change.region {
+			t.Errorf("DiffRanges()[%d] = %+v, want %+v", i, got[i], change)
+		}
+	}
+
+	if changes := DiffRanges(to, to); len(changes) != 0 {
+		t.Errorf("DiffRanges() of the same version = %+v, want no changes", changes)
+	}
+}
@@ -9,12 +9,24 @@ import (
 var appDir = util.GetAppDir(common.AppName)
 
 const DataFile = "gcp.json"
+const GoogleDataFile = "goog.json"
 const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
 
 func getDataUrl() string {
 	return "https://www.gstatic.com/ipranges/cloud.json"
 }
 
+func getGoogleDataUrl() string {
+	return "https://www.gstatic.com/ipranges/goog.json"
+}
+
 var ProviderDirectory = fmt.Sprintf("%s/%s", appDir, "gcp")
 var DataFilePathGcp = fmt.Sprintf("%s/%s", ProviderDirectory, DataFile)
 var MetadataFilePathGcp = fmt.Sprintf("%s/%s", ProviderDirectory, MetadataFile)
+var ArchiveDirectoryGcp = fmt.Sprintf("%s/%s", ProviderDirectory, ArchiveDir)
+
+var GoogleProviderDirectory = fmt.Sprintf("%s/%s", appDir, "google")
+var DataFilePathGoogle = fmt.Sprintf("%s/%s", GoogleProviderDirectory, GoogleDataFile)
+var MetadataFilePathGoogle = fmt.Sprintf("%s/%s", GoogleProviderDirectory, MetadataFile)
+var ArchiveDirectoryGoogle = fmt.Sprintf("%s/%s", GoogleProviderDirectory, ArchiveDir)
@@ -0,0 +1,174 @@
+package gcp
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// googleData keeps goog.json and the GCP data it is reduced by, so both are
+// updated under the update policy of the Google provider. A version of the
+// Google ranges is a pair of goog.json and cloud.json versions, so it is
+// archived under both signatures and a new GCP version is a new Google version.
+type googleData struct {
+	google  *IpDataManagerGcp
+	cloud   *IpDataManagerGcp
+	archive *common.DataArchive
+}
+
+func (data *googleData) EnsureDataFile() error {
+	if err := data.google.EnsureDataFile(); err != nil {
+		return err
+	}
+	if err := data.cloud.EnsureDataFile(); err != nil {
+		return fmt.Errorf("error loading the GCP ranges excluded from Google ranges: %w", err)
+	}
+	data.archiveDataFiles()
+	return nil
+}
+
+func (data *googleData) SetUpdatePolicy(policy common.UpdatePolicy) {
+	data.google.SetUpdatePolicy(policy)
+	data.cloud.SetUpdatePolicy(policy)
+}
+
+// signature returns the signatures of goog.json and cloud.json, or nothing
+// until both are known.
+func (data *googleData) signature() string {
+	google := data.google.metadata().Metadata.Signature
+	cloud := data.cloud.metadata().Metadata.Signature
+	if google == "" || cloud == "" {
+		return ""
+	}
+	return google + "+" + cloud
+}
+
+// fetchedAt returns when the later of the two data files was fetched, which
+// is when their pair became current.
+func (data *googleData) fetchedAt() time.Time {
+	fetchedAt := util.FileModTime(data.google.DataFilePath)
+	if cloudFetchedAt := util.FileModTime(data.cloud.DataFilePath); cloudFetchedAt.After(fetchedAt) {
+		return cloudFetchedAt
+	}
+	return fetchedAt
+}
+
+// archiveDataFiles keeps the Google ranges of the current data files in the
+// archive. Both files are read again and not kept loaded.
+func (data *googleData) archiveDataFiles() {
+	data.archive.Keep(data.signature(), data.fetchedAt(), func() ([]common.RangeInfo, error) {
+		return googleRanges(data.google.source().ReadRanges, data.cloud.source().ReadRanges)
+	})
+}
+
+// ranges returns the Google ranges of the loaded data files.
+func (data *googleData) ranges() ([]common.RangeInfo, error) {
+	return googleRanges(data.google.source().LoadRanges, data.cloud.source().LoadRanges)
+}
+
+// googleRanges returns the goog.json ranges that are not GCP ranges, the way
+// Google documents finding the addresses of its own services.
+func googleRanges(google, cloud func() ([]common.RangeInfo, error)) ([]common.RangeInfo, error) {
+	ranges, err := google()
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := cloud()
+	if err != nil {
+		return nil, err
+	}
+	return subtractRanges(ranges, excluded), nil
+}
+
+// GoogleProvider reports the addresses of Google itself, such as Search,
+// Gmail or Googlebot, as opposed to the addresses of GCP customers.
+type GoogleProvider struct {
+	*provider.BaseProvider
+	data *googleData
+}
+
+func NewGoogleProvider() *GoogleProvider {
+	data := &googleData{
+		google:  ipDataManagerGoogle,
+		cloud:   ipDataManagerGcp,
+		archive: &common.DataArchive{Provider: common.Google, Dir: ArchiveDirectoryGoogle},
+	}
+	return &GoogleProvider{
+		BaseProvider: provider.NewBaseProvider("Google", data, func(bp *provider.BaseProvider) error {
+			ranges, err := data.ranges()
+			if err != nil {
+				return err
+			}
+			bp.AddRanges(ranges)
+			return nil
+		}),
+		data: data,
+	}
+}
+
+// LoadRanges returns the Google ranges that are not GCP customer ranges.
+func (p *GoogleProvider) LoadRanges() ([]common.RangeInfo, error) {
+	if err := p.Initialize(); err != nil {
+		return nil, err
+	}
+	return p.data.ranges()
+}
+
+// Signature returns the signatures of the cached goog.json and GCP data.
+func (p *GoogleProvider) Signature() string {
+	return p.data.signature()
+}
+
+// Archive returns the archive of every Google data version, after adding the
+// current version to it.
+func (p *GoogleProvider) Archive() (*common.DataArchive, error) {
+	if err := p.Initialize(); err != nil {
+		return nil, err
+	}
+	p.data.archiveDataFiles()
+	return p.data.archive, nil
+}
+
+// Archived returns the archive of every Google data version kept so far.
+func (p *GoogleProvider) Archived() *common.DataArchive {
+	return p.data.archive
+}
+
+// subtractRanges returns the address space of ranges that is not covered by
+// excluded, as ranges in address order.
+func subtractRanges(ranges, excluded []common.RangeInfo) []common.RangeInfo {
+	prefixSet := func(ranges []common.RangeInfo) util.PrefixSet {
+		prefixes := make([]netip.Prefix, 0, len(ranges))
+		for _, info := range ranges {
+			prefix, err := util.ParsePrefix(info.Prefix)
+			if err != nil {
+				continue
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		return util.NewPrefixSet(prefixes...)
+	}
+
+	remaining := prefixSet(ranges).Difference(prefixSet(excluded)).Prefixes()
+	result := make([]common.RangeInfo, 0, len(remaining))
+	for _, prefix := range remaining {
+		result = append(result, common.RangeInfo{Prefix: prefix.String()})
+	}
+	return result
+}
+
+// ipDataManagerGoogle keeps goog.json. It has no archive of its own since
+// goog.json alone is not a version of the Google ranges.
+var ipDataManagerGoogle = &IpDataManagerGcp{
+	DataURI:         getGoogleDataUrl(),
+	DataFile:        GoogleDataFile,
+	DataFilePath:    DataFilePathGoogle,
+	IpRange:         IpRangeDataGcp{},
+	Name:            "Google",
+	MetadataManager: googleMetadataManager,
+}
+
+var Google = NewGoogleProvider()
@@ -0,0 +1,147 @@
+package gcp
+
+import (
+	"cloudip/common"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubtractRanges(t *testing.T) {
+	ranges := subtractRanges(
+		[]common.RangeInfo{{Prefix: "8.8.4.0/24"}, {Prefix: "34.0.0.0/15"}, {Prefix: "2001:4860::/32"}},
+		[]common.RangeInfo{{Prefix: "34.1.0.0/16", Service: "Google Cloud"}, {Prefix: "2001:4860:8000::/33"}},
+	)
+
+	want := []string{"8.8.4.0/24", "34.0.0.0/16", "2001:4860::/33"}
+	if len(ranges) != len(want) {
+		t.Fatalf("ranges = %+v, want %v", ranges, want)
+	}
+	for i, prefix := range want {
+		if ranges[i].Prefix != prefix {
+			t.Errorf("ranges[%d] = %q, want %q", i, ranges[i].Prefix, prefix)
+		}
+	}
+}
+
+func newTestGoogleData(t *testing.T, serverURL string) *googleData {
+	dir := t.TempDir()
+	oldMetadataManager := metadataManager
+	metadataManager = &common.MetadataManager{
+		MetadataFilePath: filepath.Join(dir, "gcp", ".metadata.json"),
+		ProviderDir:      filepath.Join(dir, "gcp"),
+		Metadata:         &common.CloudMetadata{Type: common.GCP},
+	}
+	t.Cleanup(func() {
+		metadataManager = oldMetadataManager
+	})
+
+	return &googleData{
+		google: &IpDataManagerGcp{
+			DataURI:      serverURL + "/goog.json",
+			DataFilePath: filepath.Join(dir, "google", "goog.json"),
+			Name:         "Google",
+			MetadataManager: &common.MetadataManager{
+				MetadataFilePath: filepath.Join(dir, "google", ".metadata.json"),
+				ProviderDir:      filepath.Join(dir, "google"),
+				Metadata:         &common.CloudMetadata{Type: common.Google},
+			},
+		},
+		cloud: &IpDataManagerGcp{
+			DataURI:      serverURL + "/cloud.json",
+			DataFilePath: filepath.Join(dir, "gcp", "gcp.json"),
+		},
+		archive: &common.DataArchive{Provider: common.Google, Dir: filepath.Join(dir, "google", "archive")},
+	}
+}
+
+func TestGoogleDataArchivesEveryCloudVersion(t *testing.T) {
+	cloudBody := `{
+		"syncToken": "cloud-1",
+		"creationTime": "2026-10-01T00:00:00",
+		"prefixes": [{"ipv4Prefix": "34.1.0.0/16", "service": "Google Cloud", "scope": "us-east1"}]
+	}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/goog.json":
+			_, _ = w.Write([]byte(`{
+				"syncToken": "goog-1",
+				"creationTime": "2026-10-01T00:00:00",
+				"prefixes": [{"ipv4Prefix": "8.8.4.0/24"}, {"ipv4Prefix": "34.0.0.0/15"}]
+			}`))
+		default:
+			_, _ = w.Write([]byte(cloudBody))
+		}
+	}))
+	defer server.Close()
+
+	data := newTestGoogleData(t, server.URL)
+	if err := data.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if signature := data.signature(); signature != "goog-1+cloud-1" {
+		t.Fatalf("signature() = %q, want goog-1+cloud-1", signature)
+	}
+	ranges, err := data.ranges()
+	if err != nil {
+		t.Fatalf("ranges() error = %v", err)
+	}
+	if len(ranges) != 2 || ranges[0].Prefix != "8.8.4.0/24" || ranges[1].Prefix != "34.0.0.0/16" {
+		t.Fatalf("ranges = %+v, want 8.8.4.0/24 and 34.0.0.0/16", ranges)
+	}
+
+	// A new cloud.json changes the Google ranges while goog.json stays the same.
+	cloudBody = `{
+		"syncToken": "cloud-2",
+		"creationTime": "2026-10-02T00:00:00",
+		"prefixes": [{"ipv4Prefix": "34.0.0.0/16"}]
+	}`
+	if err := data.cloud.metadata().MarkChecked(time.Now().Add(-48 * time.Hour)); err != nil {
+		t.Fatalf("MarkChecked() error = %v", err)
+	}
+	data.cloud.IpRange = IpRangeDataGcp{}
+	if err := data.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+
+	versions, err := data.archive.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].Signature != "goog-1+cloud-1" || versions[1].Signature != "goog-1+cloud-2" {
+		t.Fatalf("versions = %+v, want one version per cloud.json version", versions)
+	}
+	snapshot, err := data.archive.Load(versions[1])
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(snapshot.Ranges) != 2 || snapshot.Ranges[0].Prefix != "8.8.4.0/24" || snapshot.Ranges[1].Prefix != "34.1.0.0/16" {
+		t.Fatalf("archived ranges = %+v, want the ranges left by the new cloud.json", snapshot.Ranges)
+	}
+}
+
+func TestGoogleDataReportsMissingFiles(t *testing.T) {
+	data := newTestGoogleData(t, "http://127.0.0.1:0")
+	data.SetUpdatePolicy(common.UpdatePolicy{NoUpdate: true})
+
+	err := data.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), "Google IP ranges file does not exist") {
+		t.Fatalf("EnsureDataFile() error = %v, want missing goog.json error", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(data.google.DataFilePath), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(data.google.DataFilePath, []byte(`{"syncToken": "goog-1", "prefixes": []}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	err = data.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), "GCP ranges excluded from Google ranges") {
+		t.Fatalf("EnsureDataFile() error = %v, want missing GCP data error", err)
+	}
+}
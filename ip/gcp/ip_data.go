@@ -2,6 +2,7 @@ package gcp
 
 import (
 	"cloudip/common"
+	"cloudip/ip/provider"
 	"cloudip/util"
 	"errors"
 	"fmt"
@@ -15,6 +16,12 @@ type IpDataManagerGcp struct {
 	DataFilePath string
 	IpRange      IpRangeDataGcp
 	UpdatePolicy common.UpdatePolicy
+	Archive      *common.DataArchive
+
+	// The fields below let the manager keep goog.json, which has the same
+	// layout. They default to the GCP name and metadata.
+	Name            string
+	MetadataManager *common.MetadataManager
 }
 
 type IpRangeDataGcp struct {
@@ -35,7 +42,7 @@ func (ipRange IpRangeDataGcp) IsEmpty() bool {
 }
 
 func (ipDataManagerGcp *IpDataManagerGcp) downloadData() error {
-	common.VerboseOutput("Downloading GCP IP ranges...")
+	common.VerboseOutput(fmt.Sprintf("Downloading %s IP ranges...", ipDataManagerGcp.name()))
 	if ipDataManagerGcp.DataURI == "" {
 		return errors.New("cannot get DataURI")
 	}
@@ -61,6 +68,13 @@ func (ipDataManagerGcp *IpDataManagerGcp) writeData(gcpIpRangeData *IpRangeDataG
 		return errors.New("cannot get syncToken")
 	}
 
+	return ipDataManagerGcp.source().Update(func() (string, error) {
+		return gcpIpRangeData.SyncToken, ipDataManagerGcp.writeDataFile(gcpIpRangeData)
+	})
+}
+
+// writeDataFile replaces the data file and records its signature.
+func (ipDataManagerGcp *IpDataManagerGcp) writeDataFile(gcpIpRangeData *IpRangeDataGcp) error {
 	ipDataFile, err := os.OpenFile(ipDataManagerGcp.DataFilePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
 	if err != nil {
 		err = util.ErrorWithInfo(err, "error opening data file")
@@ -75,40 +89,67 @@ func (ipDataManagerGcp *IpDataManagerGcp) writeData(gcpIpRangeData *IpRangeDataG
 		return err
 	}
 
-	signatureExpired := metadataManager.IsSignatureExpired(gcpIpRangeData.SyncToken)
+	signatureExpired := ipDataManagerGcp.metadata().IsSignatureExpired(gcpIpRangeData.SyncToken)
 	metadata := common.CloudMetadata{
-		Type:        common.GCP,
+		Type:        ipDataManagerGcp.metadata().Metadata.Type,
 		Signature:   gcpIpRangeData.SyncToken,
 		LastChecked: time.Now().Unix(),
 	}
-	if err := metadataManager.Write(&metadata); err != nil {
+	if err := ipDataManagerGcp.metadata().Write(&metadata); err != nil {
 		err = util.ErrorWithInfo(err, "error writing metadata")
 		util.PrintErrorTrace(err)
 		return err
 	}
 	if signatureExpired {
-		common.VerboseOutput(fmt.Sprintf("GCP IP ranges updated [%s]", gcpIpRangeData.CreationTime))
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges updated [%s]", ipDataManagerGcp.name(), gcpIpRangeData.CreationTime))
 	}
 
 	return nil
 }
 
+// source describes the data file for loading and archiving its ranges.
+// goog.json is converted the same way as cloud.json.
+func (ipDataManagerGcp *IpDataManagerGcp) source() provider.DataSource[IpRangeDataGcp] {
+	return provider.DataSource[IpRangeDataGcp]{
+		Load:     ipDataManagerGcp.LoadIpData,
+		Read:     ipDataManagerGcp.readDataFile,
+		Convert:  rangesFromData,
+		Files:    []string{ipDataManagerGcp.DataFilePath},
+		Metadata: ipDataManagerGcp.metadata(),
+		Archive:  ipDataManagerGcp.Archive,
+	}
+}
+
+func (ipDataManagerGcp *IpDataManagerGcp) name() string {
+	if ipDataManagerGcp.Name == "" {
+		return "GCP"
+	}
+	return ipDataManagerGcp.Name
+}
+
+func (ipDataManagerGcp *IpDataManagerGcp) metadata() *common.MetadataManager {
+	if ipDataManagerGcp.MetadataManager == nil {
+		return metadataManager
+	}
+	return ipDataManagerGcp.MetadataManager
+}
+
 func (ipDataManagerGcp *IpDataManagerGcp) SetUpdatePolicy(policy common.UpdatePolicy) {
 	ipDataManagerGcp.UpdatePolicy = policy
 }
 
 func (ipDataManagerGcp *IpDataManagerGcp) EnsureDataFile() error {
-	if err := metadataManager.Ensure(); err != nil {
+	if err := ipDataManagerGcp.metadata().Ensure(); err != nil {
 		return err
 	}
-	if err := metadataManager.Read(); err != nil {
+	if err := ipDataManagerGcp.metadata().Read(); err != nil {
 		return err
 	}
 
 	if !util.IsFileExists(ipDataManagerGcp.DataFilePath) {
-		common.VerboseOutput("GCP IP ranges file does not exist.")
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges file does not exist.", ipDataManagerGcp.name()))
 		if ipDataManagerGcp.UpdatePolicy.NoUpdate {
-			return errors.New("GCP IP ranges file does not exist and --no-update is enabled")
+			return fmt.Errorf("%s IP ranges file does not exist and --no-update is enabled", ipDataManagerGcp.name())
 		}
 		err := ipDataManagerGcp.downloadData()
 		return err
@@ -116,30 +157,30 @@ func (ipDataManagerGcp *IpDataManagerGcp) EnsureDataFile() error {
 
 	policy := ipDataManagerGcp.UpdatePolicy
 	if policy.NoUpdate {
-		common.VerboseOutput("GCP IP ranges update check skipped.")
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped.", ipDataManagerGcp.name()))
 		return nil
 	}
-	if metadataManager.IsUpdateCheckFresh(time.Now(), policy.EffectiveTTL()) {
-		common.VerboseOutput("GCP IP ranges update check skipped; cache is fresh.")
+	if ipDataManagerGcp.metadata().IsUpdateCheckFresh(time.Now(), policy.EffectiveTTL()) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped; cache is fresh.", ipDataManagerGcp.name()))
 		return nil
 	}
 
 	gcpIpRangeData, err := ipDataManagerGcp.fetchData()
 	if err != nil {
-		util.PrintErrorTrace(util.ErrorWithInfo(err, "error getting signature from GCP server"))
+		util.PrintErrorTrace(util.ErrorWithInfo(err, fmt.Sprintf("error getting signature from %s server", ipDataManagerGcp.name())))
 		return nil
 	}
 	if gcpIpRangeData.SyncToken == "" {
 		return errors.New("cannot get syncToken")
 	}
-	if metadataManager.IsSignatureExpired(gcpIpRangeData.SyncToken) {
-		common.VerboseOutput("GCP IP ranges are outdated. Updating to the latest version...")
+	if ipDataManagerGcp.metadata().IsSignatureExpired(gcpIpRangeData.SyncToken) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges are outdated. Updating to the latest version...", ipDataManagerGcp.name()))
 		return ipDataManagerGcp.writeData(gcpIpRangeData)
 	}
-	if err := metadataManager.MarkChecked(time.Now()); err != nil {
+	if err := ipDataManagerGcp.metadata().MarkChecked(time.Now()); err != nil {
 		return util.ErrorWithInfo(err, "error writing metadata")
 	}
-	common.VerboseOutput("GCP IP ranges are up-to-date.")
+	common.VerboseOutput(fmt.Sprintf("%s IP ranges are up-to-date.", ipDataManagerGcp.name()))
 
 	return nil
 }
@@ -179,4 +220,5 @@ var ipDataManagerGcp = &IpDataManagerGcp{
 	DataFile:     DataFile,
 	DataFilePath: DataFilePathGcp,
 	IpRange:      IpRangeDataGcp{},
+	Archive:      &common.DataArchive{Provider: common.GCP, Dir: ArchiveDirectoryGcp},
 }
@@ -12,3 +12,12 @@ var metadataManager = &common.MetadataManager{
 		Signature: "",
 	},
 }
+
+var googleMetadataManager = &common.MetadataManager{
+	MetadataFilePath: MetadataFilePathGoogle,
+	ProviderDir:      GoogleProviderDirectory,
+	Metadata: &common.CloudMetadata{
+		Type:      common.Google,
+		Signature: "",
+	},
+}
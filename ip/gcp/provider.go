@@ -1,39 +1,42 @@
 package gcp
 
 import (
+	"cloudip/common"
 	"cloudip/ip/provider"
-	"cloudip/util"
 )
 
 type GCPProvider struct {
-	*provider.BaseProvider
+	*provider.DataProvider[IpRangeDataGcp]
 }
 
 func NewGCPProvider() *GCPProvider {
 	return &GCPProvider{
-		BaseProvider: provider.NewBaseProvider("GCP", ipDataManagerGcp, func(bp *provider.BaseProvider) error {
-			gcpIpRangeData, err := ipDataManagerGcp.LoadIpData()
-			if err != nil {
-				return err
-			}
-
-			for _, prefix := range gcpIpRangeData.Prefixes {
-				if prefix.Ipv4Prefix != "" {
-					if err := bp.AddIPv4Range(prefix.Ipv4Prefix); err != nil {
-						util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+prefix.Ipv4Prefix))
-						continue
-					}
-				} else if prefix.Ipv6Prefix != "" {
-					if err := bp.AddIPv6Range(prefix.Ipv6Prefix); err != nil {
-						util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+prefix.Ipv6Prefix))
-						continue
-					}
-				}
-			}
+		DataProvider: provider.NewDataProvider("GCP", ipDataManagerGcp, ipDataManagerGcp.source),
+	}
+}
 
-			return nil
-		}),
+// rangesFromData converts the GCP data file into ranges. The scope of a GCP
+// prefix is the region it is announced from.
+func rangesFromData(data *IpRangeDataGcp) []common.RangeInfo {
+	ranges := make([]common.RangeInfo, 0, len(data.Prefixes))
+	for _, prefix := range data.Prefixes {
+		info := common.RangeInfo{
+			Region:  prefix.Scope,
+			Service: prefix.Service,
+		}
+		if prefix.Ipv4Prefix != "" {
+			info.Prefix = prefix.Ipv4Prefix
+		} else if prefix.Ipv6Prefix != "" {
+			info.Prefix = prefix.Ipv6Prefix
+		} else {
+			continue
+		}
+		if prefix.Scope != "" {
+			info.Attributes = map[string]string{"scope": prefix.Scope}
+		}
+		ranges = append(ranges, info)
 	}
+	return ranges
 }
 
 var Provider = NewGCPProvider()
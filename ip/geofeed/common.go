@@ -0,0 +1,31 @@
+package geofeed
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"fmt"
+)
+
+var appDir = util.GetAppDir(common.AppName)
+
+const DataFile = "geofeed.csv"
+const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
+
+// Feed is a provider that publishes its address space as an RFC 8805 geofeed.
+type Feed struct {
+	Provider common.CloudProvider
+	Name     string
+	URL      string
+}
+
+// Feeds are the built-in geofeed providers.
+var Feeds = []Feed{
+	{Provider: common.DigitalOcean, Name: "DigitalOcean", URL: "https://www.digitalocean.com/geo/google.csv"},
+	{Provider: common.Linode, Name: "Linode", URL: "https://geoip.linode.com/"},
+	{Provider: common.Vultr, Name: "Vultr", URL: "https://geofeed.constant.com/?text"},
+}
+
+func providerDirectory(providerType common.CloudProvider) string {
+	return fmt.Sprintf("%s/%s", appDir, providerType)
+}
@@ -0,0 +1,236 @@
+package geofeed
+
+import (
+	"bytes"
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+type IpDataManagerGeofeed struct {
+	Name            string
+	DataURI         string
+	DataFilePath    string
+	IpRange         IpRangeDataGeofeed
+	UpdatePolicy    common.UpdatePolicy
+	MetadataManager *common.MetadataManager
+	Archive         *common.DataArchive
+}
+
+type IpRangeDataGeofeed struct {
+	Entries []Entry
+}
+
+// Entry is a row of an RFC 8805 geofeed.
+type Entry struct {
+	Prefix     string
+	Country    string // ISO 3166-1 alpha-2 code
+	Region     string // ISO 3166-2 subdivision code
+	City       string
+	PostalCode string
+}
+
+func (ipRange IpRangeDataGeofeed) IsEmpty() bool {
+	return len(ipRange.Entries) == 0
+}
+
+func (m *IpDataManagerGeofeed) downloadData() error {
+	common.VerboseOutput(fmt.Sprintf("Downloading %s IP ranges...", m.Name))
+	if m.DataURI == "" {
+		return errors.New("cannot get DataURI")
+	}
+
+	data, err := m.fetchData()
+	if err != nil {
+		return err
+	}
+	return m.writeData(data)
+}
+
+// fetchData downloads the geofeed and checks that it has at least one entry,
+// so an error page served with a 200 status does not replace the data file.
+func (m *IpDataManagerGeofeed) fetchData() ([]byte, error) {
+	data, _, err := util.DownloadFromUrl(m.DataURI)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseGeofeed(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s geofeed has no entries", m.Name)
+	}
+	return data, nil
+}
+
+func (m *IpDataManagerGeofeed) writeData(data []byte) error {
+	signature := util.ContentSignature(data)
+
+	return m.source().Update(func() (string, error) {
+		return signature, m.writeDataFile(data, signature)
+	})
+}
+
+// writeDataFile replaces the data file and records its signature.
+func (m *IpDataManagerGeofeed) writeDataFile(data []byte, signature string) error {
+	if err := os.WriteFile(m.DataFilePath, data, 0644); err != nil {
+		err = util.ErrorWithInfo(err, "error writing data file")
+		util.PrintErrorTrace(err)
+		return err
+	}
+
+	signatureExpired := m.MetadataManager.IsSignatureExpired(signature)
+	metadata := common.CloudMetadata{
+		Type:        m.MetadataManager.Metadata.Type,
+		Signature:   signature,
+		LastChecked: time.Now().Unix(),
+	}
+	if err := m.MetadataManager.Write(&metadata); err != nil {
+		err = util.ErrorWithInfo(err, "error writing metadata")
+		util.PrintErrorTrace(err)
+		return err
+	}
+	if signatureExpired {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges updated [%s]", m.Name, signature))
+	}
+
+	return nil
+}
+
+// source describes the cached geofeed for loading and archiving its ranges.
+func (m *IpDataManagerGeofeed) source() provider.DataSource[IpRangeDataGeofeed] {
+	return provider.DataSource[IpRangeDataGeofeed]{
+		Load:     m.LoadIpData,
+		Read:     m.readDataFile,
+		Convert:  rangesFromData,
+		Files:    []string{m.DataFilePath},
+		Metadata: m.MetadataManager,
+		Archive:  m.Archive,
+	}
+}
+
+func (m *IpDataManagerGeofeed) SetUpdatePolicy(policy common.UpdatePolicy) {
+	m.UpdatePolicy = policy
+}
+
+func (m *IpDataManagerGeofeed) EnsureDataFile() error {
+	if err := m.MetadataManager.Ensure(); err != nil {
+		return err
+	}
+	if err := m.MetadataManager.Read(); err != nil {
+		return err
+	}
+
+	if !util.IsFileExists(m.DataFilePath) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges file does not exist.", m.Name))
+		if m.UpdatePolicy.NoUpdate {
+			return fmt.Errorf("%s IP ranges file does not exist and --no-update is enabled", m.Name)
+		}
+		return m.downloadData()
+	}
+
+	policy := m.UpdatePolicy
+	if policy.NoUpdate {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped.", m.Name))
+		return nil
+	}
+	if m.MetadataManager.IsUpdateCheckFresh(time.Now(), policy.EffectiveTTL()) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges update check skipped; cache is fresh.", m.Name))
+		return nil
+	}
+
+	data, err := m.fetchData()
+	if err != nil {
+		util.PrintErrorTrace(util.ErrorWithInfo(err, fmt.Sprintf("error getting geofeed from %s server", m.Name)))
+		return nil
+	}
+	if m.MetadataManager.IsSignatureExpired(util.ContentSignature(data)) {
+		common.VerboseOutput(fmt.Sprintf("%s IP ranges are outdated. Updating to the latest version...", m.Name))
+		return m.writeData(data)
+	}
+	if err := m.MetadataManager.MarkChecked(time.Now()); err != nil {
+		return util.ErrorWithInfo(err, "error writing metadata")
+	}
+	common.VerboseOutput(fmt.Sprintf("%s IP ranges are up-to-date.", m.Name))
+
+	return nil
+}
+
+func (m *IpDataManagerGeofeed) LoadIpData() (*IpRangeDataGeofeed, error) {
+	if !m.IpRange.IsEmpty() {
+		return &m.IpRange, nil
+	}
+
+	data, err := m.readDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	m.IpRange = *data
+	return &m.IpRange, nil
+}
+
+func (m *IpDataManagerGeofeed) readDataFile() (*IpRangeDataGeofeed, error) {
+	ipDataFile, err := os.Open(m.DataFilePath)
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error opening data file")
+	}
+	defer ipDataFile.Close()
+
+	entries, err := parseGeofeed(ipDataFile)
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading data file")
+	}
+
+	return &IpRangeDataGeofeed{Entries: entries}, nil
+}
+
+// parseGeofeed reads the rows of an RFC 8805 geofeed: prefix, country,
+// region, city and postal code. Comment lines start with "#", trailing
+// fields may be omitted, and rows without a valid prefix are ignored as the
+// RFC requires.
+func parseGeofeed(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(i int) string {
+			if i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		prefix, err := netip.ParsePrefix(field(0))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Prefix:     prefix.String(),
+			Country:    strings.ToUpper(field(1)),
+			Region:     strings.ToUpper(field(2)),
+			City:       field(3),
+			PostalCode: field(4),
+		})
+	}
+	return entries, nil
+}
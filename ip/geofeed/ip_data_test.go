@@ -0,0 +1,159 @@
+package geofeed
+
+import (
+	"cloudip/common"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGeofeed = `# DigitalOcean geofeed
+104.131.0.0/18,US,US-NY,New York,10001
+2604:a880::/48,us,us-ny,New York,
+not-a-prefix,US,US-NY,New York,
+46.101.0.0/17,DE
+`
+
+func newTestManager(t *testing.T, url string) *IpDataManagerGeofeed {
+	dir := t.TempDir()
+	return &IpDataManagerGeofeed{
+		Name:         "DigitalOcean",
+		DataURI:      url,
+		DataFilePath: filepath.Join(dir, DataFile),
+		MetadataManager: &common.MetadataManager{
+			MetadataFilePath: filepath.Join(dir, MetadataFile),
+			ProviderDir:      dir,
+			Metadata: &common.CloudMetadata{
+				Type: common.DigitalOcean,
+			},
+		},
+	}
+}
+
+func TestParseGeofeedSkipsCommentsAndInvalidRows(t *testing.T) {
+	entries, err := parseGeofeed(strings.NewReader(testGeofeed))
+	if err != nil {
+		t.Fatalf("parseGeofeed() error = %v", err)
+	}
+
+	want := []Entry{
+		{Prefix: "104.131.0.0/18", Country: "US", Region: "US-NY", City: "New York", PostalCode: "10001"},
+		{Prefix: "2604:a880::/48", Country: "US", Region: "US-NY", City: "New York"},
+		{Prefix: "46.101.0.0/17", Country: "DE"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entries[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestGeofeedRangesFromDataKeepsLocation(t *testing.T) {
+	ranges := rangesFromData(&IpRangeDataGeofeed{Entries: []Entry{
+		{Prefix: "104.131.0.0/18", Country: "US", Region: "US-NY", City: "New York", PostalCode: "10001"},
+		{Prefix: "46.101.0.0/17", Country: "DE"},
+	}})
+
+	if len(ranges) != 2 {
+		t.Fatalf("len(ranges) = %d, want 2", len(ranges))
+	}
+	if ranges[0].Region != "US-NY" || ranges[0].Attributes["country"] != "US" || ranges[0].Attributes["city"] != "New York" ||
+		ranges[0].Attributes["postal_code"] != "10001" {
+		t.Errorf("ranges[0] = %+v, want US-NY New York 10001", ranges[0])
+	}
+	if ranges[1].Region != "DE" || len(ranges[1].Attributes) != 1 {
+		t.Errorf("ranges[1] = %+v, want region DE with only the country attribute", ranges[1])
+	}
+}
+
+func TestGeofeedEnsureDataFileDownloadsAndUpdatesOnChange(t *testing.T) {
+	body := testGeofeed
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	manager := newTestManager(t, server.URL)
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	firstSignature := manager.MetadataManager.Metadata.Signature
+	if firstSignature == "" {
+		t.Fatal("metadata signature is empty after download")
+	}
+
+	body += "165.22.0.0/17,SG,SG-01,Singapore,\n"
+	if err := manager.MetadataManager.Write(&common.CloudMetadata{
+		Type:        common.DigitalOcean,
+		Signature:   firstSignature,
+		LastChecked: time.Now().Add(-48 * time.Hour).Unix(),
+	}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if requestCount != 2 {
+		t.Fatalf("request count = %d, want 2", requestCount)
+	}
+	if manager.MetadataManager.Metadata.Signature == firstSignature {
+		t.Fatal("metadata signature unchanged after the geofeed changed")
+	}
+
+	data, err := manager.LoadIpData()
+	if err != nil {
+		t.Fatalf("LoadIpData() error = %v", err)
+	}
+	if len(data.Entries) != 4 {
+		t.Fatalf("len(Entries) = %d, want 4", len(data.Entries))
+	}
+}
+
+func TestGeofeedDownloadRejectsFeedWithoutEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>maintenance</body></html>\n"))
+	}))
+	defer server.Close()
+
+	manager := newTestManager(t, server.URL)
+	err := manager.EnsureDataFile()
+	if err == nil || !strings.Contains(err.Error(), "no entries") {
+		t.Fatalf("EnsureDataFile() error = %v, want no entries error", err)
+	}
+	if _, statErr := os.Stat(manager.DataFilePath); !os.IsNotExist(statErr) {
+		t.Fatalf("data file exists after rejected download: %v", statErr)
+	}
+}
+
+func TestGeofeedEnsureDataFileNoUpdateRequiresExistingFile(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		_, _ = w.Write([]byte(testGeofeed))
+	}))
+	defer server.Close()
+
+	manager := newTestManager(t, server.URL)
+	manager.UpdatePolicy = common.UpdatePolicy{NoUpdate: true}
+
+	err := manager.EnsureDataFile()
+	if err == nil {
+		t.Fatal("EnsureDataFile() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "--no-update") {
+		t.Fatalf("error = %v, want --no-update context", err)
+	}
+	if requestCount != 0 {
+		t.Fatalf("request count = %d, want 0", requestCount)
+	}
+}
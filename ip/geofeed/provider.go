@@ -0,0 +1,76 @@
+package geofeed
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"fmt"
+)
+
+// GeofeedProvider is a provider backed by an RFC 8805 geofeed. Every feed keeps
+// its data, metadata and archive in its own directory.
+type GeofeedProvider struct {
+	*provider.DataProvider[IpRangeDataGeofeed]
+}
+
+func NewGeofeedProvider(feed Feed) *GeofeedProvider {
+	directory := providerDirectory(feed.Provider)
+	ipDataManager := &IpDataManagerGeofeed{
+		Name:         feed.Name,
+		DataURI:      feed.URL,
+		DataFilePath: fmt.Sprintf("%s/%s", directory, DataFile),
+		MetadataManager: &common.MetadataManager{
+			MetadataFilePath: fmt.Sprintf("%s/%s", directory, MetadataFile),
+			ProviderDir:      directory,
+			Metadata: &common.CloudMetadata{
+				Type:      feed.Provider,
+				Signature: "",
+			},
+		},
+		Archive: &common.DataArchive{Provider: feed.Provider, Dir: fmt.Sprintf("%s/%s", directory, ArchiveDir)},
+	}
+
+	return &GeofeedProvider{
+		DataProvider: provider.NewDataProvider(feed.Name, ipDataManager, ipDataManager.source),
+	}
+}
+
+// rangesFromData converts the geofeed entries into ranges. The region is the
+// ISO 3166-2 subdivision when the feed has one and the country otherwise; the
+// location fields are kept as attributes.
+func rangesFromData(data *IpRangeDataGeofeed) []common.RangeInfo {
+	ranges := make([]common.RangeInfo, 0, len(data.Entries))
+	for _, entry := range data.Entries {
+		info := common.RangeInfo{
+			Prefix: entry.Prefix,
+			Region: entry.Region,
+		}
+		if info.Region == "" {
+			info.Region = entry.Country
+		}
+		for key, value := range map[string]string{
+			"country":     entry.Country,
+			"region":      entry.Region,
+			"city":        entry.City,
+			"postal_code": entry.PostalCode,
+		} {
+			if value == "" {
+				continue
+			}
+			if info.Attributes == nil {
+				info.Attributes = make(map[string]string)
+			}
+			info.Attributes[key] = value
+		}
+		ranges = append(ranges, info)
+	}
+	return ranges
+}
+
+// Providers returns a provider for every built-in feed.
+func Providers() map[common.CloudProvider]provider.CloudProvider {
+	providers := make(map[common.CloudProvider]provider.CloudProvider, len(Feeds))
+	for _, feed := range Feeds {
+		providers[feed.Provider] = NewGeofeedProvider(feed)
+	}
+	return providers
+}
@@ -0,0 +1,166 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"net"
+	"net/netip"
+	"slices"
+	"sort"
+)
+
+// Index combines the ranges of several providers into one lookup structure so
+// an address is matched against every provider with a single tree walk.
+type Index struct {
+	tree  *util.PrefixTrie[*indexEntry]
+	order map[common.CloudProvider]int
+}
+
+// indexEntry holds every provider range published for one prefix.
+type indexEntry struct {
+	matches []common.Match
+}
+
+func NewIndex(order []common.CloudProvider) *Index {
+	index := &Index{
+		tree:  util.NewPrefixTrie[*indexEntry](),
+		order: make(map[common.CloudProvider]int, len(order)),
+	}
+	for position, providerType := range order {
+		index.order[providerType] = position
+	}
+	return index
+}
+
+// Add indexes the ranges of a provider.
+func (index *Index) Add(providerType common.CloudProvider, ranges []common.RangeInfo) error {
+	for _, info := range ranges {
+		if err := index.addRange(providerType, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (index *Index) addRange(providerType common.CloudProvider, info common.RangeInfo) error {
+	prefix, err := util.ParsePrefix(info.Prefix)
+	if err != nil {
+		return err
+	}
+
+	match := common.Match{Provider: providerType, Range: info}
+	if entry, exists := index.tree.Get(prefix); exists {
+		entry.matches = append(entry.matches, match)
+		return nil
+	}
+
+	index.tree.Insert(prefix, &indexEntry{matches: []common.Match{match}})
+	return nil
+}
+
+// LookupParsedIP returns the most specific match of every provider containing
+// the parsed IP, in provider order.
+func (index *Index) LookupParsedIP(parsedIP net.IP) []common.Match {
+	entries := index.tree.AllMatchesParsedIP(parsedIP)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var matches []common.Match
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, match := range entries[i].Value.matches {
+			if _, seen := findMatch(matches, match.Provider); !seen {
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return index.order[matches[i].Provider] < index.order[matches[j].Provider]
+	})
+	return matches
+}
+
+// LookupPrefixes returns every provider range overlapping the prefixes, in
+// provider order. A range containing a whole prefix is reported once per
+// provider with its most specific prefix; ranges inside a prefix are reported
+// as the overlapping sub-prefixes. Every match carries the coverage of its
+// provider over all of the prefixes, and a partial match of a range that only
+// contains some of the prefixes reports the parts it overlaps instead.
+func (index *Index) LookupPrefixes(prefixes []netip.Prefix) []common.Match {
+	var matches []common.Match
+	covered := make(map[common.CloudProvider][]netip.Prefix)
+	type matchKey struct {
+		provider common.CloudProvider
+		prefix   string
+	}
+	seen := make(map[matchKey]bool)
+	overlaps := make(map[matchKey][]netip.Prefix)
+	add := func(match common.Match, part netip.Prefix) matchKey {
+		covered[match.Provider] = append(covered[match.Provider], part)
+		key := matchKey{provider: match.Provider, prefix: match.Range.Prefix}
+		if !seen[key] {
+			seen[key] = true
+			matches = append(matches, match)
+		}
+		return key
+	}
+
+	for _, prefix := range prefixes {
+		containing := make(map[common.CloudProvider]bool)
+		supernets := index.tree.Supernets(prefix)
+		for i := len(supernets) - 1; i >= 0; i-- {
+			for _, match := range supernets[i].Value.matches {
+				if !containing[match.Provider] {
+					containing[match.Provider] = true
+					key := add(match, prefix)
+					overlaps[key] = append(overlaps[key], prefix)
+				}
+			}
+		}
+
+		// Subnets come parents first, so a range nested in the previous part
+		// of the same provider is already covered by it.
+		for _, entry := range index.tree.Subnets(prefix) {
+			for _, match := range entry.Value.matches {
+				if containing[match.Provider] {
+					continue
+				}
+				parts := covered[match.Provider]
+				if len(parts) > 0 && parts[len(parts)-1].Contains(entry.Prefix.Addr()) && parts[len(parts)-1].Bits() <= entry.Prefix.Bits() {
+					continue
+				}
+				add(match, entry.Prefix)
+			}
+		}
+	}
+
+	target := util.AggregatePrefixes(prefixes)
+	coverage := make(map[common.CloudProvider]common.Coverage, len(covered))
+	for providerType, parts := range covered {
+		coverage[providerType] = common.CoveragePartial
+		if slices.Equal(util.AggregatePrefixes(parts), target) {
+			coverage[providerType] = common.CoverageFull
+		}
+	}
+
+	result := make([]common.Match, 0, len(matches))
+	for _, match := range matches {
+		match.Coverage = coverage[match.Provider]
+		parts := overlaps[matchKey{provider: match.Provider, prefix: match.Range.Prefix}]
+		if match.Coverage != common.CoveragePartial || len(parts) == 0 {
+			result = append(result, match)
+			continue
+		}
+		for _, part := range util.AggregatePrefixes(parts) {
+			overlap := match
+			overlap.Range.Prefix = part.String()
+			result = append(result, overlap)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return index.order[result[i].Provider] < index.order[result[j].Provider]
+	})
+	return result
+}
@@ -0,0 +1,207 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"net"
+	"testing"
+)
+
+func TestIndexLookupParsedIP(t *testing.T) {
+	index := NewIndex(DefaultProviderOrder)
+	index.Add(common.Cloudflare, []common.RangeInfo{{Prefix: "104.16.0.0/13"}})
+	index.Add(common.AWS, []common.RangeInfo{
+		{Prefix: "104.16.0.0/12", Service: "AMAZON"},
+		{Prefix: "104.16.0.0/24", Service: "EC2"},
+	})
+	index.Add(common.GCP, []common.RangeInfo{{Prefix: "104.16.0.0/24", Service: "Google Cloud"}})
+	index.Add(common.Azure, []common.RangeInfo{{Prefix: "2603:1000::/24"}})
+
+	tests := []struct {
+		name     string
+		ip       string
+		expected []string
+	}{
+		{"overlapping providers in provider order", "104.16.0.1", []string{"aws 104.16.0.0/24", "gcp 104.16.0.0/24", "cloudflare 104.16.0.0/13"}},
+		{"most specific range per provider", "104.17.0.1", []string{"aws 104.16.0.0/12", "cloudflare 104.16.0.0/13"}},
+		{"IPv6 range", "2603:1000::1", []string{"azure 2603:1000::/24"}},
+		{"no match", "192.168.1.1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := index.LookupParsedIP(net.ParseIP(tt.ip))
+			if len(matches) != len(tt.expected) {
+				t.Fatalf("Expected %d matches, got %v", len(tt.expected), matches)
+			}
+			for i, match := range matches {
+				if got := string(match.Provider) + " " + match.Range.Prefix; got != tt.expected[i] {
+					t.Errorf("Match %d: got %q, expected %q", i, got, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIndexAddRejectsInvalidCIDR(t *testing.T) {
+	index := NewIndex(DefaultProviderOrder)
+	if err := index.Add(common.AWS, []common.RangeInfo{{Prefix: "invalid-cidr"}}); err == nil {
+		t.Fatal("Expected error for invalid CIDR")
+	}
+}
+
+type countingDataManager struct {
+	calls int
+}
+
+func (m *countingDataManager) EnsureDataFile() error {
+	m.calls++
+	return nil
+}
+
+func TestCheckUsesIndexForRangeListers(t *testing.T) {
+	dataManager := &countingDataManager{}
+	loads := 0
+	aws := provider.NewBaseProvider("AWS", dataManager, func(bp *provider.BaseProvider) error {
+		loads++
+		return bp.AddCIDRRange("192.168.0.0/16")
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{
+			common.AWS: aws,
+			common.GCP: newMockProvider("GCP", true, false, false),
+		},
+		DefaultProviderOrder,
+	)
+	checker.SetMatchAll(true)
+
+	results := checker.Check([]string{"192.168.1.1", "10.1.1.1", "8.8.8.8"})
+
+	if loads != 1 || dataManager.calls != 1 {
+		t.Fatalf("Expected provider data to load once, got %d loads and %d data checks", loads, dataManager.calls)
+	}
+	if len(results[0].Matches) != 2 || results[0].Matches[0].Provider != common.AWS || results[0].Matches[1].Provider != common.GCP {
+		t.Errorf("Expected AWS and GCP matches, got %v", results[0].Matches)
+	}
+	if results[1].Provider != common.GCP {
+		t.Errorf("Expected GCP match from fallback provider, got %q", results[1].Provider)
+	}
+	if results[2].Provider != "" || results[2].Error != nil {
+		t.Errorf("Expected unknown result, got %q %v", results[2].Provider, results[2].Error)
+	}
+}
+
+func TestCheckReportsIndexInitializationError(t *testing.T) {
+	aws := provider.NewBaseProvider("AWS", &countingDataManager{}, func(bp *provider.BaseProvider) error {
+		return bp.AddCIDRRange("invalid-cidr")
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: aws},
+		DefaultProviderOrder,
+	)
+
+	result := checker.Check([]string{"8.8.8.8"})[0]
+	if result.Error == nil {
+		t.Fatal("Expected initialization error")
+	}
+}
+
+func TestIndexLookupPrefixes(t *testing.T) {
+	index := NewIndex(DefaultProviderOrder)
+	index.Add(common.AWS, []common.RangeInfo{
+		{Prefix: "13.32.0.0/16", Service: "CLOUDFRONT"},
+		{Prefix: "13.33.0.0/16", Service: "CLOUDFRONT"},
+		{Prefix: "13.33.1.0/24", Service: "EC2"},
+	})
+	index.Add(common.GCP, []common.RangeInfo{{Prefix: "13.32.128.0/17"}})
+	index.Add(common.Cloudflare, []common.RangeInfo{{Prefix: "10.0.0.0/8"}})
+	index.Add(common.Azure, []common.RangeInfo{{Prefix: "10.1.0.0/16", Service: "AzureCloud"}})
+
+	tests := []struct {
+		name     string
+		block    string
+		expected []string
+	}{
+		{"covered by adjacent ranges", "13.32.0.0/15", []string{"aws 13.32.0.0/16 full", "aws 13.33.0.0/16 full", "gcp 13.32.128.0/17 partial"}},
+		{"inside one range", "13.33.1.0/25", []string{"aws 13.33.1.0/24 full"}},
+		{"partially covered", "13.32.0.0/14", []string{"aws 13.32.0.0/16 partial", "aws 13.33.0.0/16 partial", "gcp 13.32.128.0/17 partial"}},
+		{"address range", "10.0.0.1-10.0.0.200", []string{"cloudflare 10.0.0.0/8 full"}},
+		{"address range inside part of a range", "10.0.0.1-10.1.0.5", []string{"azure 10.1.0.0/30 partial", "azure 10.1.0.4/31 partial", "cloudflare 10.0.0.0/8 full"}},
+		{"not covered", "192.168.0.0/16", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes, isBlock, err := parseBlock(tt.block)
+			if !isBlock || err != nil {
+				t.Fatalf("parseBlock(%q) = %v, %v", tt.block, isBlock, err)
+			}
+
+			matches := index.LookupPrefixes(prefixes)
+			if len(matches) != len(tt.expected) {
+				t.Fatalf("Expected %d matches, got %v", len(tt.expected), matches)
+			}
+			for i, match := range matches {
+				if got := string(match.Provider) + " " + match.Range.Prefix + " " + string(match.Coverage); got != tt.expected[i] {
+					t.Errorf("Match %d: got %q, expected %q", i, got, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseBlock(t *testing.T) {
+	tests := []struct {
+		input   string
+		isBlock bool
+		isError bool
+	}{
+		{"13.32.0.0/15", true, false},
+		{"10.0.0.1-10.0.0.200", true, false},
+		{"10.0.0.200-10.0.0.1", true, true},
+		{"10.0.0.0/33", true, true},
+		{"10.0.0.1", false, false},
+		{"not-an-ip", false, false},
+	}
+
+	for _, tt := range tests {
+		_, isBlock, err := parseBlock(tt.input)
+		if isBlock != tt.isBlock || (err != nil) != tt.isError {
+			t.Errorf("parseBlock(%q) = %v, %v, expected block %v and error %v", tt.input, isBlock, err, tt.isBlock, tt.isError)
+		}
+	}
+}
+
+func TestCheckReportsBlockCoverage(t *testing.T) {
+	aws := provider.NewBaseProvider("AWS", &countingDataManager{}, func(bp *provider.BaseProvider) error {
+		if err := bp.AddRange(common.RangeInfo{Prefix: "13.32.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"}); err != nil {
+			return err
+		}
+		return bp.AddRange(common.RangeInfo{Prefix: "13.33.0.0/16", Region: "GLOBAL", Service: "CLOUDFRONT"})
+	})
+	gcp := provider.NewBaseProvider("GCP", &countingDataManager{}, func(bp *provider.BaseProvider) error {
+		return bp.AddCIDRRange("13.34.0.0/16")
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: aws, common.GCP: gcp},
+		DefaultProviderOrder,
+	)
+
+	results := checker.Check([]string{"13.32.0.0/15", "13.32.0.0/14", "10.0.0.1-10.0.0.200", "13.32.0.0/33", "13.32.0.1"})
+
+	if results[0].Provider != common.AWS || results[0].Coverage != common.CoverageFull || len(results[0].Matches) != 2 {
+		t.Errorf("Expected full AWS coverage, got %q %q %v", results[0].Provider, results[0].Coverage, results[0].Matches)
+	}
+	if results[1].Provider != common.AWS || results[1].Coverage != common.CoveragePartial || len(results[1].Matches) != 3 {
+		t.Errorf("Expected partial AWS and GCP coverage, got %q %q %v", results[1].Provider, results[1].Coverage, results[1].Matches)
+	}
+	if results[2].Provider != "" || results[2].Coverage != common.CoverageNone || results[2].Error != nil {
+		t.Errorf("Expected no coverage, got %q %q %v", results[2].Provider, results[2].Coverage, results[2].Error)
+	}
+	if results[3].Error == nil {
+		t.Error("Expected error for invalid prefix")
+	}
+	if results[4].Coverage != "" || results[4].Range.Prefix != "13.32.0.0/16" {
+		t.Errorf("Expected single IP result without coverage, got %q %q", results[4].Coverage, results[4].Range.Prefix)
+	}
+}
@@ -0,0 +1,22 @@
+package oci
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"fmt"
+)
+
+var appDir = util.GetAppDir(common.AppName)
+
+const DataFile = "oci.json"
+const MetadataFile = ".metadata.json"
+const ArchiveDir = "archive"
+
+func getDataUrl() string {
+	return "https://docs.oracle.com/en-us/iaas/tools/public_ip_ranges.json"
+}
+
+var ProviderDirectory = fmt.Sprintf("%s/%s", appDir, "oci")
+var DataFilePathOci = fmt.Sprintf("%s/%s", ProviderDirectory, DataFile)
+var MetadataFilePathOci = fmt.Sprintf("%s/%s", ProviderDirectory, MetadataFile)
+var ArchiveDirectoryOci = fmt.Sprintf("%s/%s", ProviderDirectory, ArchiveDir)
@@ -0,0 +1,203 @@
+package oci
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+type IpDataManagerOci struct {
+	DataURI      string
+	DataFile     string
+	DataFilePath string
+	IpRange      IpRangeDataOci
+	UpdatePolicy common.UpdatePolicy
+	Archive      *common.DataArchive
+}
+
+type IpRangeDataOci struct {
+	LastUpdatedTimestamp string `json:"last_updated_timestamp"`
+	Regions              []struct {
+		Region string `json:"region"`
+		Cidrs  []struct {
+			Cidr string   `json:"cidr"`
+			Tags []string `json:"tags"`
+		} `json:"cidrs"`
+	} `json:"regions"`
+}
+
+func (ipRange IpRangeDataOci) IsEmpty() bool {
+	return ipRange.LastUpdatedTimestamp == "" &&
+		len(ipRange.Regions) == 0
+}
+
+func (ipDataManagerOci *IpDataManagerOci) downloadData() error {
+	common.VerboseOutput("Downloading OCI IP ranges...")
+	if ipDataManagerOci.DataURI == "" {
+		return errors.New("cannot get DataURI")
+	}
+
+	ociIpRangeData, err := ipDataManagerOci.fetchData()
+	if err != nil {
+		return err
+	}
+	return ipDataManagerOci.writeData(ociIpRangeData)
+}
+
+func (ipDataManagerOci *IpDataManagerOci) fetchData() (*IpRangeDataOci, error) {
+	ociIpRangeData := IpRangeDataOci{}
+	_, err := util.DownloadJSONFromUrl(ipDataManagerOci.DataURI, &ociIpRangeData)
+	if err != nil {
+		return nil, err
+	}
+	return &ociIpRangeData, nil
+}
+
+func (ipDataManagerOci *IpDataManagerOci) writeData(ociIpRangeData *IpRangeDataOci) error {
+	if ociIpRangeData.LastUpdatedTimestamp == "" {
+		return errors.New("cannot get last_updated_timestamp")
+	}
+
+	return ipDataManagerOci.source().Update(func() (string, error) {
+		return ociIpRangeData.LastUpdatedTimestamp, ipDataManagerOci.writeDataFile(ociIpRangeData)
+	})
+}
+
+// writeDataFile replaces the data file and records its signature.
+func (ipDataManagerOci *IpDataManagerOci) writeDataFile(ociIpRangeData *IpRangeDataOci) error {
+	ipDataFile, err := os.OpenFile(ipDataManagerOci.DataFilePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		err = util.ErrorWithInfo(err, "error opening data file")
+		util.PrintErrorTrace(err)
+		return err
+	}
+	defer ipDataFile.Close()
+
+	if err := util.WriteJSON(ipDataFile, ociIpRangeData); err != nil {
+		err = util.ErrorWithInfo(err, "error writing data file")
+		util.PrintErrorTrace(err)
+		return err
+	}
+
+	signatureExpired := metadataManager.IsSignatureExpired(ociIpRangeData.LastUpdatedTimestamp)
+	metadata := common.CloudMetadata{
+		Type:        common.OCI,
+		Signature:   ociIpRangeData.LastUpdatedTimestamp,
+		LastChecked: time.Now().Unix(),
+	}
+	if err := metadataManager.Write(&metadata); err != nil {
+		err = util.ErrorWithInfo(err, "error writing metadata")
+		util.PrintErrorTrace(err)
+		return err
+	}
+	if signatureExpired {
+		common.VerboseOutput(fmt.Sprintf("OCI IP ranges updated [%s]", ociIpRangeData.LastUpdatedTimestamp))
+	}
+
+	return nil
+}
+
+// source describes the OCI data file for loading and archiving its ranges.
+func (ipDataManagerOci *IpDataManagerOci) source() provider.DataSource[IpRangeDataOci] {
+	return provider.DataSource[IpRangeDataOci]{
+		Load:     ipDataManagerOci.LoadIpData,
+		Read:     ipDataManagerOci.readDataFile,
+		Convert:  rangesFromData,
+		Files:    []string{ipDataManagerOci.DataFilePath},
+		Metadata: metadataManager,
+		Archive:  ipDataManagerOci.Archive,
+	}
+}
+
+func (ipDataManagerOci *IpDataManagerOci) SetUpdatePolicy(policy common.UpdatePolicy) {
+	ipDataManagerOci.UpdatePolicy = policy
+}
+
+func (ipDataManagerOci *IpDataManagerOci) EnsureDataFile() error {
+	if err := metadataManager.Ensure(); err != nil {
+		return err
+	}
+	if err := metadataManager.Read(); err != nil {
+		return err
+	}
+
+	if !util.IsFileExists(ipDataManagerOci.DataFilePath) {
+		common.VerboseOutput("OCI IP ranges file does not exist.")
+		if ipDataManagerOci.UpdatePolicy.NoUpdate {
+			return errors.New("OCI IP ranges file does not exist and --no-update is enabled")
+		}
+		err := ipDataManagerOci.downloadData()
+		return err
+	}
+
+	policy := ipDataManagerOci.UpdatePolicy
+	if policy.NoUpdate {
+		common.VerboseOutput("OCI IP ranges update check skipped.")
+		return nil
+	}
+	if metadataManager.IsUpdateCheckFresh(time.Now(), policy.EffectiveTTL()) {
+		common.VerboseOutput("OCI IP ranges update check skipped; cache is fresh.")
+		return nil
+	}
+
+	ociIpRangeData, err := ipDataManagerOci.fetchData()
+	if err != nil {
+		util.PrintErrorTrace(util.ErrorWithInfo(err, "error getting signature from OCI server"))
+		return nil
+	}
+	if ociIpRangeData.LastUpdatedTimestamp == "" {
+		return errors.New("cannot get last_updated_timestamp")
+	}
+	if metadataManager.IsSignatureExpired(ociIpRangeData.LastUpdatedTimestamp) {
+		common.VerboseOutput("OCI IP ranges are outdated. Updating to the latest version...")
+		return ipDataManagerOci.writeData(ociIpRangeData)
+	}
+	if err := metadataManager.MarkChecked(time.Now()); err != nil {
+		return util.ErrorWithInfo(err, "error writing metadata")
+	}
+	common.VerboseOutput("OCI IP ranges are up-to-date.")
+
+	return nil
+}
+
+func (ipDataManagerOci *IpDataManagerOci) LoadIpData() (*IpRangeDataOci, error) {
+	if !ipDataManagerOci.IpRange.IsEmpty() {
+		return &ipDataManagerOci.IpRange, nil
+	}
+
+	ociIpRangeData, err := ipDataManagerOci.readDataFile()
+	if err != nil {
+		return nil, err
+	}
+
+	ipDataManagerOci.IpRange = *ociIpRangeData
+	return &ipDataManagerOci.IpRange, nil
+}
+
+func (ipDataManagerOci *IpDataManagerOci) readDataFile() (*IpRangeDataOci, error) {
+	ociIpRangeData := IpRangeDataOci{}
+	ipDataFile, err := os.Open(ipDataManagerOci.DataFilePath)
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error opening data file")
+	}
+	defer ipDataFile.Close()
+
+	err = util.ReadJSON(ipDataFile, &ociIpRangeData)
+	if err != nil {
+		return nil, util.ErrorWithInfo(err, "error reading data file")
+	}
+
+	return &ociIpRangeData, nil
+}
+
+var ipDataManagerOci = &IpDataManagerOci{
+	DataURI:      getDataUrl(),
+	DataFile:     DataFile,
+	DataFilePath: DataFilePathOci,
+	IpRange:      IpRangeDataOci{},
+	Archive:      &common.DataArchive{Provider: common.OCI, Dir: ArchiveDirectoryOci},
+}
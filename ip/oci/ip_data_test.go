@@ -0,0 +1,148 @@
+package oci
+
+import (
+	"cloudip/common"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOCIDownloadDataWritesRangesAndSignature(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"last_updated_timestamp": "2026-10-01T21:44:07.102498",
+			"regions": [
+				{"region": "us-phoenix-1", "cidrs": [{"cidr": "129.146.0.0/21", "tags": ["OCI"]}]}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	oldMetadataManager := metadataManager
+	metadataManager = &common.MetadataManager{
+		MetadataFilePath: filepath.Join(dir, ".metadata.json"),
+		ProviderDir:      dir,
+		Metadata: &common.CloudMetadata{
+			Type: common.OCI,
+		},
+	}
+	t.Cleanup(func() {
+		metadataManager = oldMetadataManager
+	})
+
+	manager := &IpDataManagerOci{
+		DataURI:      server.URL,
+		DataFilePath: filepath.Join(dir, "oci.json"),
+	}
+
+	if err := manager.EnsureDataFile(); err != nil {
+		t.Fatalf("EnsureDataFile() error = %v", err)
+	}
+	if requestCount != 1 {
+		t.Fatalf("request count = %d, want 1", requestCount)
+	}
+	if metadataManager.Metadata.Signature != "2026-10-01T21:44:07.102498" {
+		t.Fatalf("metadata signature = %q, want last_updated_timestamp", metadataManager.Metadata.Signature)
+	}
+
+	data, err := manager.LoadIpData()
+	if err != nil {
+		t.Fatalf("LoadIpData() error = %v", err)
+	}
+	if len(data.Regions) != 1 || data.Regions[0].Region != "us-phoenix-1" || data.Regions[0].Cidrs[0].Cidr != "129.146.0.0/21" {
+		t.Fatalf("Regions = %+v, want us-phoenix-1 with 129.146.0.0/21", data.Regions)
+	}
+}
+
+func TestOCILoadIpDataReturnsErrorForMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	manager := &IpDataManagerOci{
+		DataFilePath: filepath.Join(dir, "missing.json"),
+	}
+
+	if _, err := manager.LoadIpData(); err == nil {
+		t.Fatal("LoadIpData() error = nil, want error")
+	}
+}
+
+func TestOCIEnsureDataFileNoUpdateRequiresExistingFile(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"last_updated_timestamp":"2026-10-01T21:44:07.102498","regions":[]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	oldMetadataManager := metadataManager
+	metadataManager = &common.MetadataManager{
+		MetadataFilePath: filepath.Join(dir, ".metadata.json"),
+		ProviderDir:      dir,
+		Metadata: &common.CloudMetadata{
+			Type: common.OCI,
+		},
+	}
+	t.Cleanup(func() {
+		metadataManager = oldMetadataManager
+	})
+
+	manager := &IpDataManagerOci{
+		DataURI:      server.URL,
+		DataFilePath: filepath.Join(dir, "missing.json"),
+		UpdatePolicy: common.UpdatePolicy{NoUpdate: true},
+	}
+
+	err := manager.EnsureDataFile()
+	if err == nil {
+		t.Fatal("EnsureDataFile() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "--no-update") {
+		t.Fatalf("error = %v, want --no-update context", err)
+	}
+	if requestCount != 0 {
+		t.Fatalf("request count = %d, want 0", requestCount)
+	}
+}
+
+func TestOCIRangesFromDataKeepsRegionAndTags(t *testing.T) {
+	data := &IpRangeDataOci{}
+	if err := json.Unmarshal([]byte(`{
+		"last_updated_timestamp": "2026-10-01T21:44:07.102498",
+		"regions": [
+			{"region": "us-phoenix-1", "cidrs": [
+				{"cidr": "129.146.0.0/21", "tags": ["OCI"]},
+				{"cidr": "134.70.8.0/21", "tags": ["OBJECT_STORAGE", "OSN"]}
+			]},
+			{"region": "ap-seoul-1", "cidrs": [{"cidr": "132.145.80.0/20", "tags": []}]}
+		]
+	}`), data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	ranges := rangesFromData(data)
+	want := []common.RangeInfo{
+		{Prefix: "129.146.0.0/21", Region: "us-phoenix-1", Service: "OCI"},
+		{Prefix: "134.70.8.0/21", Region: "us-phoenix-1", Service: "OBJECT_STORAGE"},
+		{Prefix: "134.70.8.0/21", Region: "us-phoenix-1", Service: "OSN"},
+		{Prefix: "132.145.80.0/20", Region: "ap-seoul-1"},
+	}
+	if len(ranges) != len(want) {
+		t.Fatalf("len(ranges) = %d, want %d: %+v", len(ranges), len(want), ranges)
+	}
+	for i, info := range ranges {
+		if info.Prefix != want[i].Prefix || info.Region != want[i].Region || info.Service != want[i].Service {
+			t.Errorf("ranges[%d] = %+v, want %+v", i, info, want[i])
+		}
+		if info.Attributes["tag"] != want[i].Service {
+			t.Errorf("ranges[%d] tag = %q, want %q", i, info.Attributes["tag"], want[i].Service)
+		}
+	}
+}
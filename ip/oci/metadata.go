@@ -0,0 +1,14 @@
+package oci
+
+import (
+	"cloudip/common"
+)
+
+var metadataManager = &common.MetadataManager{
+	MetadataFilePath: MetadataFilePathOci,
+	ProviderDir:      ProviderDirectory,
+	Metadata: &common.CloudMetadata{
+		Type:      common.OCI,
+		Signature: "",
+	},
+}
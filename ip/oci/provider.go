@@ -0,0 +1,41 @@
+package oci
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+)
+
+type OCIProvider struct {
+	*provider.DataProvider[IpRangeDataOci]
+}
+
+func NewOCIProvider() *OCIProvider {
+	return &OCIProvider{
+		DataProvider: provider.NewDataProvider("OCI", ipDataManagerOci, ipDataManagerOci.source),
+	}
+}
+
+// rangesFromData converts the OCI data file into ranges. A prefix carries
+// one range per tag, such as OCI or OBJECT_STORAGE, with the tag as service.
+func rangesFromData(data *IpRangeDataOci) []common.RangeInfo {
+	var ranges []common.RangeInfo
+	for _, region := range data.Regions {
+		for _, cidr := range region.Cidrs {
+			if len(cidr.Tags) == 0 {
+				ranges = append(ranges, common.RangeInfo{Prefix: cidr.Cidr, Region: region.Region})
+				continue
+			}
+			for _, tag := range cidr.Tags {
+				ranges = append(ranges, common.RangeInfo{
+					Prefix:     cidr.Cidr,
+					Region:     region.Region,
+					Service:    tag,
+					Attributes: map[string]string{"tag": tag},
+				})
+			}
+		}
+	}
+	return ranges
+}
+
+var Provider = NewOCIProvider()
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"time"
+)
+
+// DataSource describes the cached data of a provider: how its data manager
+// reads it, how it is converted into ranges and where its metadata and
+// archive are kept.
+type DataSource[T any] struct {
+	Load     func() (*T, error)          // Returns the loaded data, reading the data files when none is kept
+	Read     func() (*T, error)          // Reads the data files without keeping the data loaded
+	Convert  func(*T) []common.RangeInfo // Converts the data into ranges
+	Files    []string                    // Data files, dating the data by their latest change
+	Metadata *common.MetadataManager
+	Archive  *common.DataArchive // Keeps every version of the data, none when nil
+}
+
+// LoadRanges converts the loaded data into ranges.
+func (source DataSource[T]) LoadRanges() ([]common.RangeInfo, error) {
+	return source.convert(source.Load)
+}
+
+// ReadRanges converts the data files as they are now into ranges.
+func (source DataSource[T]) ReadRanges() ([]common.RangeInfo, error) {
+	return source.convert(source.Read)
+}
+
+func (source DataSource[T]) convert(read func() (*T, error)) ([]common.RangeInfo, error) {
+	data, err := read()
+	if err != nil {
+		return nil, err
+	}
+	return source.Convert(data), nil
+}
+
+// ModTime returns when the data files last changed.
+func (source DataSource[T]) ModTime() time.Time {
+	var latest time.Time
+	for _, path := range source.Files {
+		if modTime := util.FileModTime(path); modTime.After(latest) {
+			latest = modTime
+		}
+	}
+	return latest
+}
+
+func (source DataSource[T]) exists() bool {
+	for _, path := range source.Files {
+		if !util.IsFileExists(path) {
+			return false
+		}
+	}
+	return len(source.Files) > 0
+}
+
+// KeepCurrent adds the data files to the archive under the signature in the
+// metadata.
+func (source DataSource[T]) KeepCurrent() {
+	source.Archive.Keep(source.Metadata.Metadata.Signature, source.ModTime(), source.ReadRanges)
+}
+
+// Update replaces the data files with write, which returns the signature of
+// the new data. The current data is archived before it is replaced and the
+// new data once it is written, so no downloaded version is lost.
+func (source DataSource[T]) Update(write func() (string, error)) error {
+	if source.exists() {
+		source.KeepCurrent()
+	}
+	signature, err := write()
+	if err != nil {
+		return err
+	}
+	source.Archive.Keep(signature, time.Now(), source.ReadRanges)
+	return nil
+}
+
+// DataProvider is a provider whose ranges are converted from its cached data.
+// It lists every published range, reports the signature of the data and
+// archives its versions, so a provider package only describes its data.
+type DataProvider[T any] struct {
+	*BaseProvider
+	source func() DataSource[T]
+}
+
+// NewDataProvider returns a provider that loads the ranges of the data
+// described by source after dataManager ensured the data file.
+func NewDataProvider[T any](name string, dataManager DataManager, source func() DataSource[T]) *DataProvider[T] {
+	return &DataProvider[T]{
+		BaseProvider: NewBaseProvider(name, dataManager, func(bp *BaseProvider) error {
+			ranges, err := source().LoadRanges()
+			if err != nil {
+				return err
+			}
+			bp.AddRanges(ranges)
+			return nil
+		}),
+		source: source,
+	}
+}
+
+// LoadRanges returns every range in the data, including prefixes published
+// more than once.
+func (p *DataProvider[T]) LoadRanges() ([]common.RangeInfo, error) {
+	if err := p.Initialize(); err != nil {
+		return nil, err
+	}
+	return p.source().LoadRanges()
+}
+
+// Signature returns the signature of the cached data.
+func (p *DataProvider[T]) Signature() string {
+	return p.source().Metadata.Metadata.Signature
+}
+
+// Archive returns the archive of every data version, after adding the current
+// version to it.
+func (p *DataProvider[T]) Archive() (*common.DataArchive, error) {
+	if err := p.Initialize(); err != nil {
+		return nil, err
+	}
+	source := p.source()
+	source.KeepCurrent()
+	return source.Archive, nil
+}
+
+// Archived returns the archive of every data version kept so far.
+func (p *DataProvider[T]) Archived() *common.DataArchive {
+	return p.source().Archive
+}
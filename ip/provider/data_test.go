@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"cloudip/common"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testData struct {
+	Prefixes []string
+}
+
+// testDataManager keeps a data file with one prefix per line.
+type testDataManager struct {
+	mockDataManager
+	path     string
+	metadata *common.MetadataManager
+	archive  *common.DataArchive
+	loaded   testData
+}
+
+func newTestDataManager(t *testing.T) *testDataManager {
+	dir := t.TempDir()
+	return &testDataManager{
+		path: filepath.Join(dir, "ranges.txt"),
+		metadata: &common.MetadataManager{
+			MetadataFilePath: filepath.Join(dir, ".metadata.json"),
+			ProviderDir:      dir,
+			Metadata:         &common.CloudMetadata{},
+		},
+		archive: &common.DataArchive{Provider: "test", Dir: filepath.Join(dir, "archive")},
+	}
+}
+
+func (m *testDataManager) load() (*testData, error) {
+	if len(m.loaded.Prefixes) == 0 {
+		data, err := m.read()
+		if err != nil {
+			return nil, err
+		}
+		m.loaded = *data
+	}
+	return &m.loaded, nil
+}
+
+func (m *testDataManager) read() (*testData, error) {
+	content, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil, err
+	}
+	return &testData{Prefixes: strings.Fields(string(content))}, nil
+}
+
+func (m *testDataManager) source() DataSource[testData] {
+	return DataSource[testData]{
+		Load: m.load,
+		Read: m.read,
+		Convert: func(data *testData) []common.RangeInfo {
+			ranges := make([]common.RangeInfo, 0, len(data.Prefixes))
+			for _, prefix := range data.Prefixes {
+				ranges = append(ranges, common.RangeInfo{Prefix: prefix})
+			}
+			return ranges
+		},
+		Files:    []string{m.path},
+		Metadata: m.metadata,
+		Archive:  m.archive,
+	}
+}
+
+// update writes the data file the way a data manager replaces its data.
+func (m *testDataManager) update(t *testing.T, signature string, prefixes ...string) {
+	t.Helper()
+	err := m.source().Update(func() (string, error) {
+		if err := os.WriteFile(m.path, []byte(strings.Join(prefixes, "\n")), 0644); err != nil {
+			return "", err
+		}
+		m.metadata.Metadata.Signature = signature
+		return signature, nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+}
+
+func TestDataProvider(t *testing.T) {
+	dm := newTestDataManager(t)
+	dm.update(t, "v1", "10.0.0.0/8", "not-a-prefix")
+	p := NewDataProvider("Test", dm, dm.source)
+
+	if err := p.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if ok, err := p.CheckParsedIP(net.ParseIP("10.1.2.3")); err != nil || !ok {
+		t.Errorf("CheckParsedIP(10.1.2.3) = %v, %v, want true", ok, err)
+	}
+	if signature := p.Signature(); signature != "v1" {
+		t.Errorf("Signature() = %q, want v1", signature)
+	}
+	if _, err := p.Archive(); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if len(dm.loaded.Prefixes) != 2 {
+		t.Errorf("loaded data = %+v, want it kept after archiving", dm.loaded)
+	}
+}
+
+func TestDataSourceUpdateArchivesEveryVersion(t *testing.T) {
+	dm := newTestDataManager(t)
+	dm.update(t, "v1", "10.0.0.0/8")
+	if _, err := dm.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	dm.update(t, "v2", "192.0.2.0/24")
+
+	// Archiving reads the files without replacing the loaded data.
+	if len(dm.loaded.Prefixes) != 1 || dm.loaded.Prefixes[0] != "10.0.0.0/8" {
+		t.Errorf("loaded data = %+v, want the data loaded before the update", dm.loaded)
+	}
+
+	versions, err := dm.archive.Versions()
+	if err != nil || len(versions) != 2 || versions[0].Signature != "v1" || versions[1].Signature != "v2" {
+		t.Fatalf("Versions() = %+v, %v, want v1 and v2", versions, err)
+	}
+	for i, want := range []string{"10.0.0.0/8", "192.0.2.0/24"} {
+		snapshot, err := dm.archive.Load(versions[i])
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(snapshot.Ranges) != 1 || snapshot.Ranges[0].Prefix != want {
+			t.Errorf("archived ranges of %s = %+v, want %s", versions[i].Signature, snapshot.Ranges, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"sync"
 	"sync/atomic"
 )
@@ -16,6 +17,36 @@ type CloudProvider interface {
 	GetName() string
 }
 
+// RangeLookuper is implemented by providers that can report the matched prefix and its attributes.
+type RangeLookuper interface {
+	LookupParsedIP(parsedIP net.IP) (common.RangeInfo, bool, error)
+}
+
+// RangeLister is implemented by providers that can list every range they loaded.
+type RangeLister interface {
+	Ranges() []common.RangeInfo
+}
+
+// RangeLoader is implemented by providers that can load every published range,
+// including prefixes published more than once with different attributes.
+type RangeLoader interface {
+	LoadRanges() ([]common.RangeInfo, error)
+}
+
+// SignatureReporter is implemented by providers that can report the signature
+// of their cached data, such as the upstream Last-Modified time or sync token.
+type SignatureReporter interface {
+	Signature() string
+}
+
+// Archiver is implemented by providers that keep every version of their data
+// in a DataArchive. Archive loads the data and adds its current version first;
+// Archived opens the archive as it is, without loading or updating the data.
+type Archiver interface {
+	Archive() (*common.DataArchive, error)
+	Archived() *common.DataArchive
+}
+
 type DataManager interface {
 	EnsureDataFile() error
 }
@@ -26,8 +57,8 @@ type UpdatePolicySetter interface {
 
 type BaseProvider struct {
 	name        string
-	v4Tree      *util.CIDRTree
-	v6Tree      *util.CIDRTree
+	v4Tree      *util.PrefixTrie[*common.RangeInfo]
+	v6Tree      *util.PrefixTrie[*common.RangeInfo]
 	initialized atomic.Bool
 	initLock    sync.Mutex
 	dataManager DataManager
@@ -53,23 +84,61 @@ func (bp *BaseProvider) GetName() string {
 }
 
 func (bp *BaseProvider) CheckParsedIP(parsedIP net.IP) (bool, error) {
+	tree, err := bp.treeForParsedIP(parsedIP)
+	if err != nil {
+		return false, err
+	}
+	return tree.ContainsParsedIP(parsedIP), nil
+}
+
+// LookupParsedIP returns the most specific range containing the parsed IP.
+func (bp *BaseProvider) LookupParsedIP(parsedIP net.IP) (common.RangeInfo, bool, error) {
+	tree, err := bp.treeForParsedIP(parsedIP)
+	if err != nil {
+		return common.RangeInfo{}, false, err
+	}
+
+	entry, ok := tree.LongestMatchParsedIP(parsedIP)
+	if !ok {
+		return common.RangeInfo{}, false, nil
+	}
+	return *entry.Value, true, nil
+}
+
+// Ranges returns the loaded ranges in address order, IPv4 first.
+func (bp *BaseProvider) Ranges() []common.RangeInfo {
+	if !bp.initialized.Load() {
+		return nil
+	}
+
+	ranges := make([]common.RangeInfo, 0, bp.v4Tree.Len()+bp.v6Tree.Len())
+	for _, tree := range []*util.PrefixTrie[*common.RangeInfo]{bp.v4Tree, bp.v6Tree} {
+		tree.Walk(func(_ netip.Prefix, info *common.RangeInfo) bool {
+			ranges = append(ranges, *info)
+			return true
+		})
+	}
+	return ranges
+}
+
+func (bp *BaseProvider) treeForParsedIP(parsedIP net.IP) (*util.PrefixTrie[*common.RangeInfo], error) {
 	if !bp.initialized.Load() {
-		return false, fmt.Errorf("provider %s is not initialized", bp.name)
+		return nil, fmt.Errorf("provider %s is not initialized", bp.name)
 	}
 
 	if parsedIP == nil {
-		return false, fmt.Errorf("error parsing IP: %v", parsedIP)
+		return nil, fmt.Errorf("error parsing IP: %v", parsedIP)
 	}
 
 	if parsedIP.To4() != nil {
-		return bp.v4Tree.MatchParsedIP(parsedIP), nil
+		return bp.v4Tree, nil
 	}
 
 	if parsedIP.To16() == nil {
-		return false, fmt.Errorf("error parsing IP: %v", parsedIP)
+		return nil, fmt.Errorf("error parsing IP: %v", parsedIP)
 	}
 
-	return bp.v6Tree.MatchParsedIP(parsedIP), nil
+	return bp.v6Tree, nil
 }
 
 func (bp *BaseProvider) Initialize() error {
@@ -84,8 +153,8 @@ func (bp *BaseProvider) Initialize() error {
 		return nil
 	}
 
-	bp.v4Tree = util.NewCIDRTree()
-	bp.v6Tree = util.NewCIDRTree()
+	bp.v4Tree = util.NewPrefixTrie[*common.RangeInfo]()
+	bp.v6Tree = util.NewPrefixTrie[*common.RangeInfo]()
 
 	err := bp.dataManager.EnsureDataFile()
 	if err != nil {
@@ -101,33 +170,75 @@ func (bp *BaseProvider) Initialize() error {
 }
 
 func (bp *BaseProvider) AddIPv4Range(cidr string) error {
+	return bp.AddIPv4RangeInfo(common.RangeInfo{Prefix: cidr})
+}
+
+func (bp *BaseProvider) AddIPv6Range(cidr string) error {
+	return bp.AddIPv6RangeInfo(common.RangeInfo{Prefix: cidr})
+}
+
+func (bp *BaseProvider) AddCIDRRange(cidr string) error {
+	return bp.AddRange(common.RangeInfo{Prefix: cidr})
+}
+
+func (bp *BaseProvider) AddIPv4RangeInfo(info common.RangeInfo) error {
 	if bp == nil {
 		return errors.New("provider is not initialized")
 	}
 	if bp.v4Tree == nil {
 		return fmt.Errorf("provider %s is not initialized", bp.name)
 	}
-	return bp.v4Tree.AddCIDR(cidr)
+	return bp.addRange(bp.v4Tree, info)
 }
 
-func (bp *BaseProvider) AddIPv6Range(cidr string) error {
+func (bp *BaseProvider) AddIPv6RangeInfo(info common.RangeInfo) error {
 	if bp == nil {
 		return errors.New("provider is not initialized")
 	}
 	if bp.v6Tree == nil {
 		return fmt.Errorf("provider %s is not initialized", bp.name)
 	}
-	return bp.v6Tree.AddCIDR(cidr)
+	return bp.addRange(bp.v6Tree, info)
 }
 
-func (bp *BaseProvider) AddCIDRRange(cidr string) error {
-	cidrVersion, err := util.GetCIDRVersion(cidr)
+// AddRange adds the range to the tree matching its IP version.
+func (bp *BaseProvider) AddRange(info common.RangeInfo) error {
+	cidrVersion, err := util.GetCIDRVersion(info.Prefix)
 	if err != nil {
 		return err
 	}
 
 	if cidrVersion == util.IPv4 {
-		return bp.AddIPv4Range(cidr)
+		return bp.AddIPv4RangeInfo(info)
 	}
-	return bp.AddIPv6Range(cidr)
+	return bp.AddIPv6RangeInfo(info)
+}
+
+// AddRanges adds every range, reporting and skipping prefixes that cannot be
+// parsed.
+func (bp *BaseProvider) AddRanges(ranges []common.RangeInfo) {
+	for _, info := range ranges {
+		if err := bp.AddRange(info); err != nil {
+			util.PrintErrorTrace(util.ErrorWithInfo(err, "error parsing CIDR: "+info.Prefix))
+		}
+	}
+}
+
+// addRange stores the range once per prefix. A prefix published more than once
+// keeps the first non-empty value of each attribute.
+func (bp *BaseProvider) addRange(tree *util.PrefixTrie[*common.RangeInfo], info common.RangeInfo) error {
+	prefix, err := util.ParsePrefix(info.Prefix)
+	if err != nil {
+		return err
+	}
+
+	if existing, exists := tree.Get(prefix); exists {
+		existing.Merge(info)
+		return nil
+	}
+
+	stored := info
+	stored.Prefix = prefix.String()
+	tree.Insert(prefix, &stored)
+	return nil
 }
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"cloudip/common"
 	"errors"
 	"fmt"
 	"net"
@@ -487,3 +488,111 @@ func BenchmarkBaseProvider_CheckParsedIP_With100Ranges_IPv6(b *testing.B) {
 		}
 	}
 }
+
+func TestBaseProvider_LookupParsedIP(t *testing.T) {
+	bp := NewBaseProvider("TestProvider", &mockDataManager{}, func(bp *BaseProvider) error { return nil })
+	if err := bp.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize provider: %v", err)
+	}
+
+	bp.AddRange(common.RangeInfo{Prefix: "10.0.0.0/8", Region: "global"})
+	bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Service: "EC2"})
+	bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Region: "us-east-1", Service: "AMAZON"})
+	bp.AddIPv6Range("2001:db8::/32")
+
+	tests := []struct {
+		name      string
+		ip        net.IP
+		wantMatch bool
+		want      common.RangeInfo
+	}{
+		{
+			name:      "Most specific prefix wins",
+			ip:        mustParseIP(t, "10.1.2.3"),
+			wantMatch: true,
+			want:      common.RangeInfo{Prefix: "10.1.0.0/16", Region: "us-east-1", Service: "EC2"},
+		},
+		{
+			name:      "Covering prefix",
+			ip:        mustParseIP(t, "10.2.0.1"),
+			wantMatch: true,
+			want:      common.RangeInfo{Prefix: "10.0.0.0/8", Region: "global"},
+		},
+		{
+			name:      "Range without attributes",
+			ip:        mustParseIP(t, "2001:db8::1"),
+			wantMatch: true,
+			want:      common.RangeInfo{Prefix: "2001:db8::/32"},
+		},
+		{
+			name: "No match",
+			ip:   mustParseIP(t, "192.168.1.1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, match, err := bp.LookupParsedIP(tt.ip)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if match != tt.wantMatch {
+				t.Fatalf("Expected match %v, got %v", tt.wantMatch, match)
+			}
+			if got.Prefix != tt.want.Prefix || got.Region != tt.want.Region || got.Service != tt.want.Service {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBaseProvider_Ranges(t *testing.T) {
+	bp := NewBaseProvider("TestProvider", &mockDataManager{}, func(bp *BaseProvider) error {
+		bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Service: "EC2"})
+		bp.AddRange(common.RangeInfo{Prefix: "10.0.0.0/8"})
+		bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Region: "us-east-1"})
+		return nil
+	})
+
+	if ranges := bp.Ranges(); ranges != nil {
+		t.Fatalf("Expected no ranges before Initialize, got %v", ranges)
+	}
+	if err := bp.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize provider: %v", err)
+	}
+
+	ranges := bp.Ranges()
+	if len(ranges) != 2 {
+		t.Fatalf("Expected 2 ranges, got %v", ranges)
+	}
+	if ranges[0].Prefix != "10.0.0.0/8" || ranges[1].Prefix != "10.1.0.0/16" {
+		t.Errorf("Expected ranges sorted by prefix, got %v", ranges)
+	}
+	if ranges[1].Service != "EC2" || ranges[1].Region != "us-east-1" {
+		t.Errorf("Expected merged attributes, got %+v", ranges[1])
+	}
+}
+
+func TestBaseProvider_AddRangeDoesNotChangeSharedAttributes(t *testing.T) {
+	shared := map[string]string{"tag": "AzureCloud"}
+	bp := NewBaseProvider("TestProvider", &mockDataManager{}, func(bp *BaseProvider) error {
+		bp.AddRange(common.RangeInfo{Prefix: "10.0.0.0/8", Attributes: shared})
+		bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Attributes: shared})
+		bp.AddRange(common.RangeInfo{Prefix: "10.1.0.0/16", Attributes: map[string]string{"platform": "Azure"}})
+		return nil
+	})
+	if err := bp.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize provider: %v", err)
+	}
+
+	ranges := bp.Ranges()
+	if ranges[1].Attributes["platform"] != "Azure" {
+		t.Errorf("Expected merged platform attribute, got %v", ranges[1].Attributes)
+	}
+	if _, exists := ranges[0].Attributes["platform"]; exists {
+		t.Errorf("Expected other ranges of the tag to be unchanged, got %v", ranges[0].Attributes)
+	}
+	if len(shared) != 1 {
+		t.Errorf("Expected shared attributes to be unchanged, got %v", shared)
+	}
+}
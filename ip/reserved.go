@@ -0,0 +1,64 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/util"
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+//go:generate go run reserved_gen.go
+
+// reservedBlock is a block of an IANA special-purpose address registry.
+type reservedBlock struct {
+	prefix            string
+	name              string
+	rfc               string
+	globallyReachable bool
+}
+
+var reservedTrie = sync.OnceValue(func() *util.PrefixTrie[*reservedBlock] {
+	trie := util.NewPrefixTrie[*reservedBlock]()
+	for i := range reservedBlocks {
+		prefix := netip.MustParsePrefix(reservedBlocks[i].prefix)
+		if prefix.Addr().Is4In6() {
+			// Addresses are unmapped before the lookup, and the trie would
+			// store ::ffff:0:0/96 as 0.0.0.0/0.
+			continue
+		}
+		trie.Insert(prefix, &reservedBlocks[i])
+	}
+	return trie
+})
+
+// LookupReserved returns the most specific special-purpose block containing
+// the address when the address is not globally reachable, such as a private,
+// loopback or documentation address. The service of the range is the
+// category, e.g. "private-use", and its attributes hold the registry name and
+// the RFC that reserved the block.
+func LookupReserved(addr netip.Addr) (common.RangeInfo, bool) {
+	entry, ok := reservedTrie().LongestMatch(addr.Unmap().WithZone(""))
+	if !ok || entry.Value.globallyReachable {
+		return common.RangeInfo{}, false
+	}
+
+	return common.RangeInfo{
+		Prefix:     entry.Prefix.String(),
+		Service:    reservedCategory(entry.Value.name),
+		Attributes: map[string]string{"name": entry.Value.name, "rfc": entry.Value.rfc},
+	}, true
+}
+
+// reservedCategory turns a registry name into a label such as "private-use"
+// or "link-local". Parenthesized details, like the TEST-NET number of a
+// documentation block, are dropped.
+func reservedCategory(name string) string {
+	if index := strings.Index(name, "("); index >= 0 {
+		name = name[:index]
+	}
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,125 @@
+//go:build ignore
+
+// This program generates reserved_table.go from the IANA IPv4 and IPv6
+// Special-Purpose Address Registries. Run it with "go generate ./ip".
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var registries = []string{
+	"https://www.iana.org/assignments/iana-ipv4-special-registry/iana-ipv4-special-registry-1.csv",
+	"https://www.iana.org/assignments/iana-ipv6-special-registry/iana-ipv6-special-registry-1.csv",
+}
+
+// multicastBlocks are not listed in the special-purpose registries, but
+// multicast addresses are never the address of a single public host either.
+var multicastBlocks = []block{
+	{prefix: "224.0.0.0/4", name: "Multicast", rfc: "RFC 5771"},
+	{prefix: "ff00::/8", name: "Multicast", rfc: "RFC 4291"},
+}
+
+type block struct {
+	prefix            string
+	name              string
+	rfc               string
+	globallyReachable bool
+}
+
+var (
+	footnotePattern = regexp.MustCompile(`\s*\[\d+\]`)
+	rfcPattern      = regexp.MustCompile(`RFC\s*(\d+)`)
+)
+
+func main() {
+	var blocks []block
+	for _, url := range registries {
+		registry, err := fetchRegistry(url)
+		if err != nil {
+			log.Fatalf("error reading %s: %v", url, err)
+		}
+		blocks = append(blocks, registry...)
+	}
+	blocks = append(blocks, multicastBlocks...)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by reserved_gen.go; DO NOT EDIT.\n\npackage ip\n\n")
+	buf.WriteString("// reservedBlocks are the blocks of the IANA IPv4 and IPv6 Special-Purpose\n")
+	buf.WriteString("// Address Registries, followed by the multicast blocks.\n")
+	buf.WriteString("var reservedBlocks = []reservedBlock{\n")
+	for _, b := range blocks {
+		fmt.Fprintf(&buf, "\t{%q, %q, %q, %t},\n", b.prefix, b.name, b.rfc, b.globallyReachable)
+	}
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("error formatting table: %v", err)
+	}
+	if err := os.WriteFile("reserved_table.go", source, 0644); err != nil {
+		log.Fatalf("error writing table: %v", err)
+	}
+}
+
+// fetchRegistry reads the blocks of a registry CSV. A row can list several
+// blocks, and values can carry footnote markers such as "[2]".
+func fetchRegistry(url string) ([]block, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("registry has no entries")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"Address Block", "Name", "RFC", "Globally Reachable"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("registry has no %q column", name)
+		}
+	}
+
+	var blocks []block
+	for _, record := range records[1:] {
+		name := strings.Join(strings.Fields(footnotePattern.ReplaceAllString(record[columns["Name"]], "")), " ")
+		name = strings.Trim(name, `"`)
+		rfc := rfcPattern.FindStringSubmatch(record[columns["RFC"]])
+		if rfc == nil {
+			return nil, fmt.Errorf("%s has no RFC", name)
+		}
+		// "N/A" marks tunnel prefixes such as 6to4 whose reachability depends on
+		// the embedded address, so only "False" blocks are not globally reachable.
+		reachable := !strings.HasPrefix(strings.TrimSpace(record[columns["Globally Reachable"]]), "False")
+
+		for _, value := range strings.Split(footnotePattern.ReplaceAllString(record[columns["Address Block"]], ""), ",") {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			blocks = append(blocks, block{prefix: prefix.String(), name: name, rfc: "RFC " + rfc[1], globallyReachable: reachable})
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,70 @@
+package ip
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestLookupReserved(t *testing.T) {
+	tests := []struct {
+		addr     string
+		prefix   string
+		category string
+		rfc      string
+	}{
+		{"10.20.30.40", "10.0.0.0/8", "private-use", "RFC 1918"},
+		{"100.64.1.1", "100.64.0.0/10", "shared-address-space", "RFC 6598"},
+		{"127.0.0.1", "127.0.0.0/8", "loopback", "RFC 1122"},
+		{"169.254.169.254", "169.254.0.0/16", "link-local", "RFC 3927"},
+		{"192.0.2.1", "192.0.2.0/24", "documentation", "RFC 5737"},
+		{"192.0.0.8", "192.0.0.8/32", "ipv4-dummy-address", "RFC 7600"},
+		{"0.0.0.0", "0.0.0.0/32", "this-host-on-this-network", "RFC 1122"},
+		{"239.255.255.250", "224.0.0.0/4", "multicast", "RFC 5771"},
+		{"255.255.255.255", "255.255.255.255/32", "limited-broadcast", "RFC 8190"},
+		{"::ffff:10.0.0.1", "10.0.0.0/8", "private-use", "RFC 1918"},
+		{"::1", "::1/128", "loopback-address", "RFC 4291"},
+		{"fd12:3456::1", "fc00::/7", "unique-local", "RFC 4193"},
+		{"fe80::1%eth0", "fe80::/10", "link-local-unicast", "RFC 4291"},
+		{"64:ff9b:1::a00:1", "64:ff9b:1::/48", "ipv4-ipv6-translat", "RFC 8215"},
+		{"2001:db8::1", "2001:db8::/32", "documentation", "RFC 3849"},
+		{"ff02::1", "ff00::/8", "multicast", "RFC 4291"},
+		// Globally reachable special-purpose blocks are not reserved.
+		{"192.0.0.9", "", "", ""},
+		{"2001:4:112::1", "", "", ""},
+		{"64:ff9b::a00:1", "", "", ""},
+		{"2002:a00:1::1", "", "", ""},
+		{"2001:0:4136:e378:8000:63bf:3fff:fdd2", "", "", ""},
+		{"8.8.8.8", "", "", ""},
+		{"2600:9000::1", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			info, reserved := LookupReserved(netip.MustParseAddr(tt.addr))
+			if reserved != (tt.prefix != "") {
+				t.Fatalf("LookupReserved(%s) = %+v, %v, want reserved %v", tt.addr, info, reserved, tt.prefix != "")
+			}
+			if !reserved {
+				return
+			}
+			if info.Prefix != tt.prefix || info.Service != tt.category || info.Attributes["rfc"] != tt.rfc {
+				t.Errorf("LookupReserved(%s) = %+v, want %s %s %s", tt.addr, info, tt.prefix, tt.category, tt.rfc)
+			}
+		})
+	}
+}
+
+func TestReservedCategory(t *testing.T) {
+	tests := map[string]string{
+		"Private-Use":                    "private-use",
+		"Documentation (TEST-NET-1)":     "documentation",
+		"NAT64/DNS64 Discovery":          "nat64-dns64-discovery",
+		"Segment Routing (SRv6) SIDs":    "segment-routing",
+		"IPv4 Service Continuity Prefix": "ipv4-service-continuity-prefix",
+	}
+	for name, expected := range tests {
+		if got := reservedCategory(name); got != expected {
+			t.Errorf("reservedCategory(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
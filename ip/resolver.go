@@ -0,0 +1,96 @@
+package ip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultResolveTimeout bounds a single hostname lookup.
+const DefaultResolveTimeout = 5 * time.Second
+
+// Resolver looks up the A and AAAA records of a hostname. *net.Resolver
+// implements it, and tests can inject a local stand-in.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewResolver returns the system resolver, or a resolver that sends every
+// query to the DNS server at address (host:port) when one is given.
+func NewResolver(address string) (Resolver, error) {
+	if address == "" {
+		return net.DefaultResolver, nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid resolver address %q: %w", address, err)
+	}
+
+	dialer := &net.Dialer{Timeout: DefaultResolveTimeout}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}, nil
+}
+
+// resolveHost returns the unique addresses of the host, IPv4 first.
+func resolveHost(resolver Resolver, host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultResolveTimeout)
+	defer cancel()
+
+	ipAddrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving hostname: %w", err)
+	}
+
+	seen := make(map[string]bool, len(ipAddrs))
+	var addresses []net.IP
+	for _, ipAddr := range ipAddrs {
+		if seen[ipAddr.IP.String()] {
+			continue
+		}
+		seen[ipAddr.IP.String()] = true
+		addresses = append(addresses, ipAddr.IP)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("error resolving hostname: no addresses found for %s", host)
+	}
+
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].To4() != nil && addresses[j].To4() == nil
+	})
+	values := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		values = append(values, address.String())
+	}
+	return values, nil
+}
+
+// isHostname reports whether the input looks like a DNS name such as
+// "api.example.com". Single labels and names ending in a numeric label are
+// rejected so malformed IPs are still reported as IP parse errors.
+func isHostname(value string) bool {
+	value = strings.TrimSuffix(value, ".")
+	if len(value) == 0 || len(value) > 253 || !strings.Contains(value, ".") {
+		return false
+	}
+
+	labels := strings.Split(value, ".")
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, char := range label {
+			if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-') {
+				return false
+			}
+		}
+	}
+
+	last := labels[len(labels)-1]
+	return strings.Trim(last, "0123456789") != ""
+}
@@ -0,0 +1,135 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// fakeResolver is a local DNS stand-in answering from a fixed table.
+type fakeResolver struct {
+	records map[string][]string
+	lookups []string
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	r.lookups = append(r.lookups, host)
+	values, exists := r.records[host]
+	if !exists {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addrs := make([]net.IPAddr, 0, len(values))
+	for _, value := range values {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(value)})
+	}
+	return addrs, nil
+}
+
+func TestIsHostname(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"api.example.com", true},
+		{"api.example.com.", true},
+		{"my-vendor.co.uk", true},
+		{"xn--bcher-kva.example", true},
+		{"localhost", false},
+		{"bad-ip", false},
+		{"999.999.999.999", false},
+		{"10.0.0", false},
+		{"-bad.example.com", false},
+		{"bad..example.com", false},
+		{"under_score.example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := isHostname(tt.input); got != tt.expected {
+			t.Errorf("isHostname(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	resolver, err := NewResolver("")
+	if err != nil || resolver != net.DefaultResolver {
+		t.Errorf("Expected system resolver, got %v, %v", resolver, err)
+	}
+
+	resolver, err = NewResolver("127.0.0.1:5353")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if custom, ok := resolver.(*net.Resolver); !ok || custom.Dial == nil {
+		t.Errorf("Expected resolver dialing the given server, got %v", resolver)
+	}
+
+	if _, err := NewResolver("127.0.0.1"); err == nil {
+		t.Error("Expected error for resolver address without port")
+	}
+}
+
+func TestResolveHostDeduplicatesAndOrdersAddresses(t *testing.T) {
+	resolver := &fakeResolver{records: map[string][]string{
+		"api.example.com": {"2001:db8::1", "192.0.2.10", "192.0.2.10", "192.0.2.11"},
+	}}
+
+	addresses, err := resolveHost(resolver, "api.example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"192.0.2.10", "192.0.2.11", "2001:db8::1"}
+	if len(addresses) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, addresses)
+	}
+	for i := range expected {
+		if addresses[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, addresses)
+		}
+	}
+}
+
+func TestCheckResolvesHostnames(t *testing.T) {
+	aws := provider.NewBaseProvider("AWS", &countingDataManager{}, func(bp *provider.BaseProvider) error {
+		return bp.AddRange(common.RangeInfo{Prefix: "192.0.2.0/24", Region: "us-east-1", Service: "EC2"})
+	})
+	checker := NewIPChecker(
+		map[common.CloudProvider]provider.CloudProvider{common.AWS: aws},
+		DefaultProviderOrder,
+	)
+	resolver := &fakeResolver{records: map[string][]string{
+		"api.example.com": {"192.0.2.10", "2001:db8::1"},
+	}}
+	checker.SetResolver(resolver)
+
+	results := checker.Check([]string{"api.example.com", "missing.example.com", "192.0.2.1", "bad-ip"})
+
+	host := results[0]
+	if host.Host != "api.example.com" || host.Error != nil || len(host.Addresses) != 2 {
+		t.Fatalf("Expected two resolved addresses, got %+v", host)
+	}
+	if host.Addresses[0].Ip != "192.0.2.10" || host.Addresses[0].Provider != common.AWS || host.Addresses[0].Range.Service != "EC2" {
+		t.Errorf("Expected AWS match for 192.0.2.10, got %+v", host.Addresses[0])
+	}
+	if host.Addresses[1].Ip != "2001:db8::1" || host.Addresses[1].Provider != common.Reserved {
+		t.Errorf("Expected reserved result for 2001:db8::1, got %+v", host.Addresses[1])
+	}
+
+	var dnsErr *net.DNSError
+	if results[1].Host != "missing.example.com" || !errors.As(results[1].Error, &dnsErr) {
+		t.Errorf("Expected resolution error, got %+v", results[1])
+	}
+	if results[2].Host != "" || results[2].Provider != common.AWS {
+		t.Errorf("Expected plain IP result, got %+v", results[2])
+	}
+	if results[3].Host != "" || results[3].Error == nil {
+		t.Errorf("Expected IP parse error, got %+v", results[3])
+	}
+	if len(resolver.lookups) != 2 {
+		t.Errorf("Expected only hostnames to be resolved, got lookups %v", resolver.lookups)
+	}
+}
@@ -0,0 +1,151 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"cloudip/util"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// AddressEventKind describes how an address changed between archived versions.
+type AddressEventKind string
+
+const (
+	// AddressPresent marks an address that was in the oldest archived version.
+	AddressPresent AddressEventKind = "present"
+	// AddressEntered marks the version that added an address.
+	AddressEntered AddressEventKind = "entered"
+	// AddressLeft marks the version that removed an address.
+	AddressLeft AddressEventKind = "left"
+	// AddressChanged marks a version that moved an address to another range,
+	// region or service.
+	AddressChanged AddressEventKind = "changed"
+)
+
+// AddressEvent is a change in the range that contains an address. Range is the
+// new range, or the range the address left.
+type AddressEvent struct {
+	Version common.ArchivedVersion
+	Kind    AddressEventKind
+	Range   common.RangeInfo
+}
+
+// snapshotData is the data manager of a provider answering from an archived
+// version. The version is loaded when the provider is initialized.
+type snapshotData struct {
+	archive *common.DataArchive
+	at      time.Time
+	ranges  []common.RangeInfo
+}
+
+func (data *snapshotData) EnsureDataFile() error {
+	version, found, err := data.archive.VersionAt(data.at)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no data archived at or before %s", data.at.Format(time.RFC3339))
+	}
+
+	snapshot, err := data.archive.Load(version)
+	if err != nil {
+		return err
+	}
+	common.VerboseOutput(fmt.Sprintf("Using %s IP ranges [%s] fetched at %s", data.archive.Provider, version.Signature, version.FetchedAt.Format(time.RFC3339)))
+	data.ranges = snapshot.Ranges
+	return nil
+}
+
+// AsOf returns a checker that answers lookups with the provider data that was
+// current at the given time. Only the archives are read: the live data is
+// neither loaded nor updated. Providers without archived data at that time,
+// such as providers added later, are left out with a verbose note.
+func (c *IPChecker) AsOf(at time.Time) *IPChecker {
+	providers := make(map[common.CloudProvider]provider.CloudProvider, len(c.providers))
+	for providerType, p := range c.providers {
+		var archive *common.DataArchive
+		if archiver, ok := p.(provider.Archiver); ok {
+			archive = archiver.Archived()
+		}
+		if archive == nil {
+			common.VerboseOutput(fmt.Sprintf("%s IP ranges are not archived; skipped.", p.GetName()))
+			continue
+		}
+		if _, found, err := archive.VersionAt(at); err == nil && !found {
+			common.VerboseOutput(fmt.Sprintf("No %s IP ranges archived at or before %s; skipped.", p.GetName(), at.Format(time.RFC3339)))
+			continue
+		}
+
+		data := &snapshotData{archive: archive, at: at}
+		providers[providerType] = provider.NewBaseProvider(p.GetName(), data, func(bp *provider.BaseProvider) error {
+			for _, info := range data.ranges {
+				if err := bp.AddRange(info); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	checker := NewIPChecker(providers, c.providerOrder)
+	checker.updatePolicy = c.updatePolicy
+	checker.matchAll = c.matchAll
+	checker.strategy = c.strategy
+	checker.resolver = c.resolver
+	return checker
+}
+
+// AddressHistory returns the versions in which the archived ranges containing
+// the address changed, oldest first. Each version is matched by its most
+// specific prefix containing the address.
+func AddressHistory(archive *common.DataArchive, addr netip.Addr) ([]AddressEvent, error) {
+	versions, err := archive.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []AddressEvent
+	var previous *common.RangeInfo
+	for i, version := range versions {
+		snapshot, err := archive.Load(version)
+		if err != nil {
+			return nil, err
+		}
+		current, found := longestMatch(snapshot.Ranges, addr)
+
+		switch {
+		case found && previous == nil && i == 0:
+			events = append(events, AddressEvent{Version: version, Kind: AddressPresent, Range: current})
+		case found && previous == nil:
+			events = append(events, AddressEvent{Version: version, Kind: AddressEntered, Range: current})
+		case !found && previous != nil:
+			events = append(events, AddressEvent{Version: version, Kind: AddressLeft, Range: *previous})
+		case found && rangeKeyOf(current) != rangeKeyOf(*previous):
+			events = append(events, AddressEvent{Version: version, Kind: AddressChanged, Range: current})
+		}
+
+		previous = nil
+		if found {
+			previous = &current
+		}
+	}
+	return events, nil
+}
+
+// longestMatch returns the most specific range containing the address. Ranges
+// with the same prefix keep the first one.
+func longestMatch(ranges []common.RangeInfo, addr netip.Addr) (common.RangeInfo, bool) {
+	var match common.RangeInfo
+	bits := -1
+	for _, info := range ranges {
+		prefix, err := util.ParsePrefix(info.Prefix)
+		if err != nil || prefix.Bits() <= bits || !prefix.Contains(addr) {
+			continue
+		}
+		match = info
+		bits = prefix.Bits()
+	}
+	return match, bits >= 0
+}
@@ -0,0 +1,143 @@
+package ip
+
+import (
+	"cloudip/common"
+	"cloudip/ip/provider"
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+type archivingProvider struct {
+	*provider.BaseProvider
+	archive *common.DataArchive
+}
+
+func (p *archivingProvider) Archive() (*common.DataArchive, error) {
+	if err := p.Initialize(); err != nil {
+		return nil, err
+	}
+	return p.archive, nil
+}
+
+func (p *archivingProvider) Archived() *common.DataArchive {
+	return p.archive
+}
+
+// liveDataManager fails the lookups that load or update the live data.
+type liveDataManager struct {
+	calls int
+}
+
+func (m *liveDataManager) EnsureDataFile() error {
+	m.calls++
+	return errors.New("live data loaded")
+}
+
+func newArchivingProvider(name string, live *liveDataManager, archive *common.DataArchive) *archivingProvider {
+	return &archivingProvider{
+		BaseProvider: provider.NewBaseProvider(name, live, func(bp *provider.BaseProvider) error {
+			return bp.AddCIDRRange("52.0.0.0/8")
+		}),
+		archive: archive,
+	}
+}
+
+// newTestArchive archives one version per day from 2026-03-01.
+func newTestArchive(t *testing.T, versions ...[]common.RangeInfo) *common.DataArchive {
+	t.Helper()
+	archive := &common.DataArchive{Provider: common.AWS, Dir: t.TempDir()}
+	fetchedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, ranges := range versions {
+		signature := fmt.Sprintf("v%d", i+1)
+		if err := archive.Save(signature, fetchedAt.AddDate(0, 0, i), ranges); err != nil {
+			t.Fatalf("Save(%s) error = %v", signature, err)
+		}
+	}
+	return archive
+}
+
+func TestAddressHistory(t *testing.T) {
+	archive := newTestArchive(t,
+		[]common.RangeInfo{{Prefix: "3.5.0.0/16", Region: "us-east-1", Service: "AMAZON"}},
+		[]common.RangeInfo{{Prefix: "3.5.0.0/16", Region: "US-EAST-1", Service: "amazon"}, {Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"}},
+		[]common.RangeInfo{{Prefix: "13.32.0.0/15"}},
+		[]common.RangeInfo{{Prefix: "13.32.0.0/15"}},
+		[]common.RangeInfo{{Prefix: "3.5.0.0/19", Region: "us-east-2", Service: "S3"}},
+	)
+
+	events, err := AddressHistory(archive, netip.MustParseAddr("3.5.1.1"))
+	if err != nil {
+		t.Fatalf("AddressHistory() error = %v", err)
+	}
+	want := []struct {
+		signature string
+		kind      AddressEventKind
+		prefix    string
+		region    string
+	}{
+		{"v1", AddressPresent, "3.5.0.0/16", "us-east-1"},
+		{"v2", AddressChanged, "3.5.0.0/19", "us-east-1"},
+		{"v3", AddressLeft, "3.5.0.0/19", "us-east-1"},
+		{"v5", AddressEntered, "3.5.0.0/19", "us-east-2"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("AddressHistory() = %+v, want %d events", events, len(want))
+	}
+	for i, event := range want {
+		got := events[i]
+		if got.Version.Signature != event.signature || got.Kind != event.kind || got.Range.Prefix != event.prefix || got.Range.Region != event.region {
+			t.Errorf("AddressHistory()[%d] = %+v, want %+v", i, got, event)
+		}
+	}
+
+	events, err = AddressHistory(archive, netip.MustParseAddr("8.8.8.8"))
+	if err != nil || len(events) != 0 {
+		t.Errorf("AddressHistory(8.8.8.8) = %+v, %v, want no events", events, err)
+	}
+}
+
+func TestCheckerAsOfUsesArchivedVersion(t *testing.T) {
+	live := &liveDataManager{}
+	aws := newArchivingProvider("AWS", live, newTestArchive(t,
+		[]common.RangeInfo{{Prefix: "3.5.0.0/19", Region: "us-east-1", Service: "S3"}},
+		[]common.RangeInfo{{Prefix: "13.32.0.0/15", Region: "GLOBAL", Service: "CLOUDFRONT"}},
+	))
+	// GCP was archived for the first time on 2026-03-03.
+	gcpArchive := &common.DataArchive{Provider: common.GCP, Dir: t.TempDir()}
+	if err := gcpArchive.Save("g1", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), []common.RangeInfo{{Prefix: "34.0.0.0/16"}}); err != nil {
+		t.Fatalf("Save(g1) error = %v", err)
+	}
+	gcp := newArchivingProvider("GCP", live, gcpArchive)
+	oci := newMockProvider("OCI", true, false, false)
+	checker := NewIPChecker(map[common.CloudProvider]provider.CloudProvider{common.AWS: aws, common.GCP: gcp, common.OCI: oci}, DefaultProviderOrder)
+
+	results := checker.AsOf(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)).Check([]string{"3.5.1.1", "34.0.0.1", "52.0.0.1"})
+	if results[0].Provider != common.AWS || results[0].Range.Service != "S3" || results[0].Error != nil {
+		t.Errorf("3.5.1.1 as of v1 = %+v, want AWS S3", results[0])
+	}
+	for _, result := range results[1:] {
+		if result.Provider != "" || result.Error != nil {
+			t.Errorf("%s as of v1 = %+v, want no match and no error from providers without data then", result.Ip, result)
+		}
+	}
+
+	results = checker.AsOf(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)).Check([]string{"13.32.0.1", "34.0.0.1"})
+	if results[0].Provider != common.AWS || results[0].Range.Service != "CLOUDFRONT" {
+		t.Errorf("13.32.0.1 as of v2 = %+v, want AWS CLOUDFRONT", results[0])
+	}
+	if results[1].Provider != common.GCP || results[1].Error != nil {
+		t.Errorf("34.0.0.1 as of g1 = %+v, want GCP", results[1])
+	}
+
+	results = checker.AsOf(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)).Check([]string{"3.5.1.1"})
+	if results[0].Provider != "" || results[0].Error != nil {
+		t.Errorf("3.5.1.1 before the archives = %+v, want no match and no error", results[0])
+	}
+
+	if live.calls != 0 {
+		t.Errorf("live data loaded %d times, want lookups from the archives only", live.calls)
+	}
+}
@@ -7,28 +7,55 @@ import (
 	"cloudip/ip/aws"
 	"cloudip/ip/azure"
 	"cloudip/ip/cloudflare"
+	"cloudip/ip/custom"
 	"cloudip/ip/gcp"
+	"cloudip/ip/geofeed"
+	"cloudip/ip/oci"
 	"cloudip/ip/provider"
 	"cloudip/util"
 	"os"
+	"slices"
 )
 
 func main() {
 	util.EnsureAppDir(common.AppName)
 
 	flags := &common.CloudIpFlag{}
-	checker := ip.NewIPChecker(
-		map[common.CloudProvider]provider.CloudProvider{
-			common.AWS:        aws.Provider,
-			common.GCP:        gcp.Provider,
-			common.Azure:      azure.Provider,
-			common.Cloudflare: cloudflare.Provider,
-		},
-		ip.DefaultProviderOrder,
-	)
+	providers := map[common.CloudProvider]provider.CloudProvider{
+		common.AWS:        aws.Provider,
+		common.GCP:        gcp.Provider,
+		common.Google:     gcp.Google,
+		common.Azure:      azure.Provider,
+		common.Cloudflare: cloudflare.Provider,
+		common.OCI:        oci.Provider,
+	}
+	for providerType, p := range geofeed.Providers() {
+		providers[providerType] = p
+	}
+
+	checker := ip.NewIPChecker(providers, slices.Clone(ip.DefaultProviderOrder))
+	checker.SetProviderLoader(loadCustomProviders)
 
 	if err := cmd.NewRootCmd(flags, checker).Execute(); err != nil {
 		util.PrintErrorTrace(err)
 		os.Exit(1)
 	}
 }
+
+// loadCustomProviders returns the providers of the config file. It is only
+// called by the commands that check addresses, so a broken config file does
+// not break the others, such as version and help.
+func loadCustomProviders() (map[common.CloudProvider]provider.CloudProvider, []common.CloudProvider, error) {
+	customProviders, err := custom.LoadProviders(custom.ConfigPath(), ip.DefaultProviderOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providers := make(map[common.CloudProvider]provider.CloudProvider, len(customProviders))
+	order := make([]common.CloudProvider, 0, len(customProviders))
+	for _, p := range customProviders {
+		providers[p.Type] = p
+		order = append(order, p.Type)
+	}
+	return providers, order, nil
+}
@@ -1,7 +1,10 @@
 package util
 
 import (
+	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"os"
 	"strings"
 	"sync"
@@ -102,3 +105,182 @@ func captureStdout(t *testing.T, fn func()) string {
 	}
 	return result.output
 }
+
+func TestLookupParsedIPReturnsMostSpecificCIDR(t *testing.T) {
+	tree := NewCIDRTree()
+	tree.AddCIDR("10.0.0.0/8")
+	tree.AddCIDR("10.1.0.0/16")
+
+	if cidr, ok := tree.LookupParsedIP(net.ParseIP("10.1.2.3")); !ok || cidr != "10.1.0.0/16" {
+		t.Fatalf("LookupParsedIP(10.1.2.3) = %q, %v, want 10.1.0.0/16", cidr, ok)
+	}
+	if cidr, ok := tree.LookupParsedIP(net.ParseIP("10.2.0.1")); !ok || cidr != "10.0.0.0/8" {
+		t.Fatalf("LookupParsedIP(10.2.0.1) = %q, %v, want 10.0.0.0/8", cidr, ok)
+	}
+	if _, ok := tree.LookupParsedIP(net.ParseIP("192.168.0.1")); ok {
+		t.Fatal("LookupParsedIP(192.168.0.1) matched, want no match")
+	}
+}
+
+// mapCIDRTree is the previous map-per-bit CIDR tree, kept to compare load
+// time and memory against the prefix trie.
+type mapCIDRTree struct {
+	Children map[byte]*mapCIDRTree
+	IsLeaf   bool
+	CIDR     string
+}
+
+func newMapCIDRTree() *mapCIDRTree {
+	return &mapCIDRTree{
+		Children: make(map[byte]*mapCIDRTree),
+	}
+}
+
+func (tree *mapCIDRTree) AddCIDR(cidr string) error {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+
+	maskSize, _ := ipNet.Mask.Size()
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		ip = ipNet.IP.To16()
+	}
+
+	node := tree
+	for i := 0; i < maskSize; i++ {
+		bit := (ip[i/8] >> (7 - i%8)) & 1
+		if node.Children[bit] == nil {
+			node.Children[bit] = newMapCIDRTree()
+		}
+		node = node.Children[bit]
+	}
+	node.IsLeaf = true
+	node.CIDR = cidr
+	return nil
+}
+
+func (tree *mapCIDRTree) MatchParsedIP(parsedIP net.IP) bool {
+	ipBytes := parsedIP.To4()
+	if ipBytes == nil {
+		ipBytes = parsedIP.To16()
+	}
+
+	node := tree
+	for _, octet := range ipBytes {
+		for bitIndex := 7; bitIndex >= 0; bitIndex-- {
+			if node.IsLeaf {
+				return true
+			}
+			node = node.Children[(octet>>bitIndex)&1]
+			if node == nil {
+				return false
+			}
+		}
+	}
+	return node.IsLeaf
+}
+
+// benchmarkCIDRs returns a deterministic mix of IPv4 and IPv6 prefixes shaped
+// like the Azure service tags.
+func benchmarkCIDRs(count int) []string {
+	random := rand.New(rand.NewSource(1))
+	cidrs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if i%4 == 3 {
+			cidrs = append(cidrs, fmt.Sprintf("2603:%x:%x::/%d", random.Intn(0x10000), random.Intn(0x10000), 44+random.Intn(21)))
+			continue
+		}
+		cidrs = append(cidrs, fmt.Sprintf("%d.%d.%d.%d/%d", 1+random.Intn(222), random.Intn(256), random.Intn(256), random.Intn(256), 16+random.Intn(17)))
+	}
+	return cidrs
+}
+
+func TestCIDRTreeMatchesMapCIDRTree(t *testing.T) {
+	cidrs := benchmarkCIDRs(2000)
+	tree := NewCIDRTree()
+	legacy := newMapCIDRTree()
+	for _, cidr := range cidrs {
+		tree.AddCIDR(cidr)
+		legacy.AddCIDR(cidr)
+	}
+
+	random := rand.New(rand.NewSource(2))
+	for i := 0; i < 20000; i++ {
+		var parsedIP net.IP
+		if i%2 == 0 {
+			parsedIP = net.IPv4(byte(1+random.Intn(222)), byte(random.Intn(256)), byte(random.Intn(256)), byte(random.Intn(256)))
+		} else {
+			_, ipNet, _ := net.ParseCIDR(cidrs[random.Intn(len(cidrs))])
+			parsedIP = ipNet.IP
+		}
+		if got, want := tree.MatchParsedIP(parsedIP), legacy.MatchParsedIP(parsedIP); got != want {
+			t.Fatalf("MatchParsedIP(%v) = %v, legacy tree = %v", parsedIP, got, want)
+		}
+	}
+}
+
+func BenchmarkCIDRTree_Load(b *testing.B) {
+	cidrs := benchmarkCIDRs(20000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := NewCIDRTree()
+		for _, cidr := range cidrs {
+			if err := tree.AddCIDR(cidr); err != nil {
+				b.Fatalf("AddCIDR(%q) error = %v", cidr, err)
+			}
+		}
+	}
+}
+
+func BenchmarkMapCIDRTree_Load(b *testing.B) {
+	cidrs := benchmarkCIDRs(20000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := newMapCIDRTree()
+		for _, cidr := range cidrs {
+			if err := tree.AddCIDR(cidr); err != nil {
+				b.Fatalf("AddCIDR(%q) error = %v", cidr, err)
+			}
+		}
+	}
+}
+
+func BenchmarkCIDRTree_MatchParsedIP_Loaded(b *testing.B) {
+	cidrs := benchmarkCIDRs(20000)
+	tree := NewCIDRTree()
+	for _, cidr := range cidrs {
+		tree.AddCIDR(cidr)
+	}
+	parsedIP := mustParseIP(b, "40.112.0.1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.MatchParsedIP(parsedIP)
+	}
+}
+
+func BenchmarkMapCIDRTree_MatchParsedIP_Loaded(b *testing.B) {
+	cidrs := benchmarkCIDRs(20000)
+	tree := newMapCIDRTree()
+	for _, cidr := range cidrs {
+		tree.AddCIDR(cidr)
+	}
+	parsedIP := mustParseIP(b, "40.112.0.1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.MatchParsedIP(parsedIP)
+	}
+}
@@ -80,3 +80,27 @@ func DownloadJSONFromUrl[T any](url string, data *T) (http.Header, error) {
 
 	return resp.Header, nil
 }
+
+func DownloadFromUrl(url string) ([]byte, http.Header, error) {
+	resp, err := downloadClient.Get(url)
+	if err != nil {
+		PrintErrorTrace(ErrorWithInfo(err, "error downloading data"))
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := ErrorWithInfo(fmt.Errorf("received non-200 status code: %s", resp.Status), "error downloading data")
+		PrintErrorTrace(err)
+		return nil, nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = ErrorWithInfo(err, "error reading data")
+		PrintErrorTrace(err)
+		return nil, nil, err
+	}
+
+	return data, resp.Header, nil
+}
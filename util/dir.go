@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 func GetAppDir(appName string) string {
@@ -47,3 +48,13 @@ func IsFileExists(path string) bool {
 	}
 	return !info.IsDir() // Exist and not a directory
 }
+
+// FileModTime returns the modification time of the file, or the current time
+// when it cannot be read.
+func FileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Now()
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"net/netip"
+)
+
+// maxAddressLength is the length of the longest textual IPv6 address,
+// "ffff:ffff:ffff:ffff:ffff:ffff:255.255.255.255".
+const maxAddressLength = 45
+
+// AddressSpan is an IP address found in text. Start and End are byte offsets
+// of the address text.
+type AddressSpan struct {
+	Start int
+	End   int
+	Addr  netip.Addr
+}
+
+// FindAddresses returns every IPv4 and IPv6 address in free-form text, such
+// as log lines. Candidates are runs of hex digits, ':' and '.', so addresses
+// wrapped in brackets or followed by a port ("[2001:db8::1]:443",
+// "203.0.113.5:8080") are found as well.
+func FindAddresses(text string) []AddressSpan {
+	var spans []AddressSpan
+	for start := 0; start < len(text); {
+		if !isAddressChar(text[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(text) && isAddressChar(text[end]) {
+			end++
+		}
+		if start == 0 || !isWordChar(text[start-1]) {
+			spans = appendRunAddresses(spans, text, start, end)
+		}
+		start = end
+	}
+	return spans
+}
+
+// appendRunAddresses finds the addresses inside one candidate run. An address
+// starts at the run start or after a ':' (a port or label prefix) and ends at
+// the run end, before a ':' or before a sentence-ending '.', so neither
+// "1.2.3.45" nor "1.2.3.45.6" yields an address.
+func appendRunAddresses(spans []AddressSpan, text string, runStart, runEnd int) []AddressSpan {
+	if runEnd < len(text) && isWordChar(text[runEnd]) {
+		return spans
+	}
+
+	for start := runStart; start < runEnd; start++ {
+		if start > runStart && text[start-1] != ':' {
+			continue
+		}
+		for end := min(runEnd, start+maxAddressLength); end > start+2; end-- {
+			if end < runEnd && text[end] != ':' && (text[end] != '.' || end+1 != runEnd) {
+				continue
+			}
+			addr, err := netip.ParseAddr(text[start:end])
+			if err != nil {
+				continue
+			}
+			spans = append(spans, AddressSpan{Start: start, End: end, Addr: addr})
+			start = end
+			break
+		}
+	}
+	return spans
+}
+
+func isAddressChar(char byte) bool {
+	return isHexDigit(char) || char == ':' || char == '.'
+}
+
+func isHexDigit(char byte) bool {
+	return char >= '0' && char <= '9' || char >= 'a' && char <= 'f' || char >= 'A' && char <= 'F'
+}
+
+// isWordChar reports characters that make a candidate part of a longer word,
+// like a hostname or an identifier.
+func isWordChar(char byte) bool {
+	return char >= 'g' && char <= 'z' || char >= 'G' && char <= 'Z' || char == '_'
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestFindAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "nginx access log",
+			text:     `203.0.113.5 - - [18/Oct/2026:10:15:30 +0000] "GET /index.html HTTP/1.1" 200 612 "-" "curl/8.5.0"`,
+			expected: []string{"203.0.113.5"},
+		},
+		{
+			name:     "IPv6 with brackets and port",
+			text:     "connect from [2001:db8::1]:443 to 2001:db8:0:0:0:0:0:2.",
+			expected: []string{"2001:db8::1", "2001:db8:0:0:0:0:0:2"},
+		},
+		{
+			name:     "IPv4 with port and IPv4-mapped IPv6",
+			text:     "SRC=10.0.0.1:51234 DST=::ffff:192.0.2.1 PROTO=TCP",
+			expected: []string{"10.0.0.1", "::ffff:192.0.2.1"},
+		},
+		{
+			name:     "compressed IPv6 forms",
+			text:     "lo ::1 fe80::1ff:fe23:4567:890a, 2001:db8::",
+			expected: []string{"::1", "fe80::1ff:fe23:4567:890a", "2001:db8::"},
+		},
+		{
+			name:     "URL and CIDR",
+			text:     "http://198.51.100.7/path allow 10.0.0.0/8",
+			expected: []string{"198.51.100.7", "10.0.0.0"},
+		},
+		{
+			name:     "not addresses",
+			text:     "Oct 18 10:15:30 host nginx/1.18.0 1.2.3.45.6 T12:34:56 Foo::bar :: 00:1a:2b:3c:4d:5e 256.1.1.1",
+			expected: nil,
+		},
+		{
+			name:     "sentence end",
+			text:     "blocked 1.2.3.45.",
+			expected: []string{"1.2.3.45"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := FindAddresses(tt.text)
+			if len(spans) != len(tt.expected) {
+				t.Fatalf("FindAddresses(%q) = %v, want %v", tt.text, spans, tt.expected)
+			}
+			for i, span := range spans {
+				if tt.text[span.Start:span.End] != tt.expected[i] || !span.Addr.IsValid() {
+					t.Errorf("span %d = %q (%s), want %q", i, tt.text[span.Start:span.End], span.Addr, tt.expected[i])
+				}
+			}
+		})
+	}
+}
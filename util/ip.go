@@ -1,11 +1,29 @@
 package util
 
-import "net"
+import (
+	"net"
+	"net/netip"
+)
 
 var IPv4 int8 = 4
 var IPv6 int8 = 6
 var InvalidIPVersion int8 = 0
 
+// IPv6 transition formats an IPv4 address can be embedded in.
+const (
+	Embedding6to4           = "6to4"
+	EmbeddingTeredo         = "teredo"
+	EmbeddingNAT64          = "nat64"
+	EmbeddingIPv4Compatible = "ipv4-compatible"
+)
+
+var (
+	prefix6to4           = netip.MustParsePrefix("2002::/16")
+	prefixTeredo         = netip.MustParsePrefix("2001::/32")
+	prefixNAT64          = netip.MustParsePrefix("64:ff9b::/96")
+	prefixIPv4Compatible = netip.MustParsePrefix("::/96")
+)
+
 func GetCIDRVersion(cidr string) (int8, error) {
 	_, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
@@ -16,3 +34,32 @@ func GetCIDRVersion(cidr string) (int8, error) {
 	}
 	return IPv6, nil
 }
+
+// EmbeddedIPv4 returns the IPv4 address embedded in a 6to4, Teredo, NAT64
+// (well-known prefix) or IPv4-compatible IPv6 address, and the format it was
+// extracted from. IPv4-mapped addresses are plain IPv4 addresses to the
+// checker and are not reported, nor are :: and ::1.
+func EmbeddedIPv4(addr netip.Addr) (netip.Addr, string, bool) {
+	if !addr.Is6() || addr.Is4In6() {
+		return netip.Addr{}, "", false
+	}
+	bytes := addr.As16()
+
+	switch {
+	case prefix6to4.Contains(addr):
+		// 2002:AABB:CCDD::/48 carries the site's IPv4 address AA.BB.CC.DD.
+		return netip.AddrFrom4([4]byte(bytes[2:6])), Embedding6to4, true
+	case prefixTeredo.Contains(addr):
+		// The last 32 bits are the client's public IPv4 address with every bit inverted.
+		var client [4]byte
+		for i := range client {
+			client[i] = bytes[12+i] ^ 0xff
+		}
+		return netip.AddrFrom4(client), EmbeddingTeredo, true
+	case prefixNAT64.Contains(addr):
+		return netip.AddrFrom4([4]byte(bytes[12:])), EmbeddingNAT64, true
+	case prefixIPv4Compatible.Contains(addr) && bytes[12] != 0:
+		return netip.AddrFrom4([4]byte(bytes[12:])), EmbeddingIPv4Compatible, true
+	}
+	return netip.Addr{}, "", false
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestEmbeddedIPv4(t *testing.T) {
+	tests := []struct {
+		addr      string
+		embedded  string
+		embedding string
+	}{
+		{"2002:c000:204::1", "192.0.2.4", Embedding6to4},
+		{"2001:0:4136:e378:8000:63bf:3fff:fdd2", "192.0.2.45", EmbeddingTeredo},
+		{"64:ff9b::3605:1", "54.5.0.1", EmbeddingNAT64},
+		{"64:ff9b::192.0.2.33", "192.0.2.33", EmbeddingNAT64},
+		{"::203.0.113.9", "203.0.113.9", EmbeddingIPv4Compatible},
+		{"::ffff:192.0.2.1", "", ""},
+		{"::", "", ""},
+		{"::1", "", ""},
+		{"64:ff9b:1::c000:201", "", ""},
+		{"2600:9000::1", "", ""},
+		{"192.0.2.1", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			embedded, embedding, ok := EmbeddedIPv4(netip.MustParseAddr(tt.addr))
+			if ok != (tt.embedded != "") {
+				t.Fatalf("EmbeddedIPv4(%s) ok = %v, want %v", tt.addr, ok, tt.embedded != "")
+			}
+			if !ok {
+				return
+			}
+			if embedded.String() != tt.embedded || embedding != tt.embedding {
+				t.Errorf("EmbeddedIPv4(%s) = %s, %s, want %s, %s", tt.addr, embedded, embedding, tt.embedded, tt.embedding)
+			}
+		})
+	}
+}
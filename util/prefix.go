@@ -0,0 +1,198 @@
+package util
+
+import (
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// ParseAddressRange parses an inclusive address range such as
+// "10.0.0.1-10.0.0.200" into the minimal list of prefixes covering it.
+func ParseAddressRange(value string) ([]netip.Prefix, error) {
+	startValue, endValue, found := strings.Cut(value, "-")
+	if !found {
+		return nil, fmt.Errorf("invalid address range %q", value)
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(startValue))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address range %q: %w", value, err)
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(endValue))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address range %q: %w", value, err)
+	}
+	return RangeToPrefixes(start.Unmap(), end.Unmap())
+}
+
+// RangeToPrefixes returns the minimal list of prefixes covering the inclusive
+// range from start to end.
+func RangeToPrefixes(start, end netip.Addr) ([]netip.Prefix, error) {
+	if start.Is4() != end.Is4() {
+		return nil, fmt.Errorf("address range %s-%s mixes IPv4 and IPv6", start, end)
+	}
+	if end.Less(start) {
+		return nil, fmt.Errorf("address range %s-%s ends before it starts", start, end)
+	}
+
+	var prefixes []netip.Prefix
+	for {
+		bits := start.BitLen()
+		for bits > 0 {
+			candidate := netip.PrefixFrom(start, bits-1)
+			if candidate.Masked().Addr() != start || end.Less(PrefixLastAddr(candidate)) {
+				break
+			}
+			bits--
+		}
+
+		prefix := netip.PrefixFrom(start, bits)
+		prefixes = append(prefixes, prefix)
+
+		last := PrefixLastAddr(prefix)
+		if last == end {
+			return prefixes, nil
+		}
+		start = last.Next()
+	}
+}
+
+// PrefixLastAddr returns the last address inside the prefix.
+func PrefixLastAddr(prefix netip.Prefix) netip.Addr {
+	prefix = prefix.Masked()
+	octets := addrBytes(prefix.Addr())
+	for i := prefix.Bits(); i < prefix.Addr().BitLen(); i++ {
+		octets[i/8] |= 1 << (7 - i%8)
+	}
+
+	if prefix.Addr().Is4() {
+		return netip.AddrFrom4([4]byte(octets[:4]))
+	}
+	return netip.AddrFrom16(octets)
+}
+
+// SortPrefixes sorts prefixes in address order, IPv4 first, with shorter
+// prefixes before the longer prefixes they contain.
+func SortPrefixes(prefixes []netip.Prefix) {
+	sort.Slice(prefixes, func(i, j int) bool {
+		return ComparePrefixes(prefixes[i], prefixes[j]) < 0
+	})
+}
+
+// ComparePrefixes orders prefixes by address, then by prefix length.
+func ComparePrefixes(a, b netip.Prefix) int {
+	if cmp := a.Addr().Compare(b.Addr()); cmp != 0 {
+		return cmp
+	}
+	return a.Bits() - b.Bits()
+}
+
+// AggregatePrefixes returns the minimal sorted set of prefixes covering the
+// same addresses. Nested prefixes are dropped and adjacent prefixes are merged.
+func AggregatePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	sorted := make([]netip.Prefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		sorted = append(sorted, normalizePrefix(prefix))
+	}
+	SortPrefixes(sorted)
+
+	result := make([]netip.Prefix, 0, len(sorted))
+	for _, prefix := range sorted {
+		if len(result) > 0 && result[len(result)-1].Overlaps(prefix) {
+			continue
+		}
+		result = append(result, prefix)
+
+		for len(result) >= 2 {
+			parent, ok := mergeSiblings(result[len(result)-2], result[len(result)-1])
+			if !ok {
+				break
+			}
+			result = append(result[:len(result)-2], parent)
+		}
+	}
+	return result
+}
+
+// mergeSiblings returns the parent of two prefixes that are the lower and
+// upper half of it.
+func mergeSiblings(lower, upper netip.Prefix) (netip.Prefix, bool) {
+	if lower.Bits() != upper.Bits() || lower.Bits() == 0 || lower.Addr().Is4() != upper.Addr().Is4() {
+		return netip.Prefix{}, false
+	}
+	parent := netip.PrefixFrom(lower.Addr(), lower.Bits()-1).Masked()
+	if parent.Addr() != lower.Addr() || !parent.Contains(upper.Addr()) {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
+
+// SummarizePrefixes aggregates the prefixes and then, while more than
+// maxEntries remain, replaces neighbours by their longest common supernet,
+// closest neighbours first. Unlike AggregatePrefixes the result may cover
+// addresses outside the input, but never misses one. IPv4 and IPv6 prefixes
+// are never merged, so a mixed list can stay above maxEntries.
+func SummarizePrefixes(prefixes []netip.Prefix, maxEntries int) []netip.Prefix {
+	result := AggregatePrefixes(prefixes)
+	for maxEntries > 0 && len(result) > maxEntries {
+		longest := -1
+		for i := 0; i+1 < len(result); i++ {
+			if parent, ok := commonSupernet(result[i], result[i+1]); ok {
+				longest = max(longest, parent.Bits())
+			}
+		}
+		if longest < 0 {
+			break
+		}
+
+		// Every pair at the longest supernet length is merged in one pass,
+		// left to right, until few enough entries remain. A new supernet
+		// swallows the prefixes that follow inside it.
+		merged := make([]netip.Prefix, 0, len(result))
+		for i, prefix := range result {
+			if len(merged) > 0 && merged[len(merged)-1].Contains(prefix.Addr()) {
+				continue
+			}
+			remaining := len(merged) + len(result) - i
+			if len(merged) > 0 && remaining > maxEntries {
+				if parent, ok := commonSupernet(merged[len(merged)-1], prefix); ok && parent.Bits() == longest {
+					merged = merged[:len(merged)-1]
+					prefix = parent
+				}
+			}
+			merged = append(merged, prefix)
+			for len(merged) >= 2 {
+				parent, ok := mergeSiblings(merged[len(merged)-2], merged[len(merged)-1])
+				if !ok {
+					break
+				}
+				merged = append(merged[:len(merged)-2], parent)
+			}
+		}
+		result = merged
+	}
+	return result
+}
+
+// commonSupernet returns the longest prefix containing both prefixes.
+func commonSupernet(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Addr().Is4() != b.Addr().Is4() {
+		return netip.Prefix{}, false
+	}
+
+	x, y := a.Addr().As16(), b.Addr().As16()
+	common := 0
+	for i := range x {
+		if diff := x[i] ^ y[i]; diff != 0 {
+			common += bits.LeadingZeros8(diff)
+			break
+		}
+		common += 8
+	}
+	if a.Addr().Is4() {
+		common -= 128 - 32
+	}
+	return netip.PrefixFrom(a.Addr(), min(common, a.Bits(), b.Bits())).Masked(), true
+}
@@ -0,0 +1,110 @@
+package util
+
+import "net/netip"
+
+// PrefixSet is an immutable set of addresses, stored as the minimal sorted
+// list of prefixes covering them. The zero value is the empty set.
+type PrefixSet struct {
+	prefixes []netip.Prefix // Aggregated, as returned by AggregatePrefixes
+}
+
+// NewPrefixSet returns the set of addresses covered by the prefixes.
+func NewPrefixSet(prefixes ...netip.Prefix) PrefixSet {
+	return PrefixSet{prefixes: AggregatePrefixes(prefixes)}
+}
+
+// Prefixes returns the minimal sorted list of prefixes covering the set.
+func (set PrefixSet) Prefixes() []netip.Prefix {
+	return append([]netip.Prefix(nil), set.prefixes...)
+}
+
+// Len returns the number of prefixes in the minimal list.
+func (set PrefixSet) Len() int {
+	return len(set.prefixes)
+}
+
+// Contains reports whether the address is in the set.
+func (set PrefixSet) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range set.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+		if addr.Less(prefix.Addr()) {
+			break
+		}
+	}
+	return false
+}
+
+// Union returns the addresses in either set.
+func (set PrefixSet) Union(other PrefixSet) PrefixSet {
+	prefixes := make([]netip.Prefix, 0, len(set.prefixes)+len(other.prefixes))
+	prefixes = append(prefixes, set.prefixes...)
+	prefixes = append(prefixes, other.prefixes...)
+	return NewPrefixSet(prefixes...)
+}
+
+// Intersect returns the addresses in both sets.
+func (set PrefixSet) Intersect(other PrefixSet) PrefixSet {
+	// Two prefixes either nest or are disjoint, so every overlap of the
+	// sorted lists contributes the more specific of the two prefixes.
+	var prefixes []netip.Prefix
+	a, b := set.prefixes, other.prefixes
+	for len(a) > 0 && len(b) > 0 {
+		if a[0].Overlaps(b[0]) {
+			if a[0].Bits() >= b[0].Bits() {
+				prefixes = append(prefixes, a[0])
+			} else {
+				prefixes = append(prefixes, b[0])
+			}
+		}
+		if PrefixLastAddr(a[0]).Less(PrefixLastAddr(b[0])) {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return NewPrefixSet(prefixes...)
+}
+
+// Difference returns the addresses in the set that are not in the other set.
+func (set PrefixSet) Difference(other PrefixSet) PrefixSet {
+	var prefixes []netip.Prefix
+	b := other.prefixes
+	for _, prefix := range set.prefixes {
+		for len(b) > 0 && PrefixLastAddr(b[0]).Less(prefix.Addr()) {
+			b = b[1:]
+		}
+		var overlapping []netip.Prefix
+		for _, candidate := range b {
+			if !candidate.Overlaps(prefix) {
+				break
+			}
+			overlapping = append(overlapping, candidate)
+		}
+		prefixes = subtractPrefixes(prefixes, prefix, overlapping)
+	}
+	return NewPrefixSet(prefixes...)
+}
+
+// subtractPrefixes appends the parts of the prefix outside the sorted,
+// disjoint excluded prefixes, halving the prefix until every part is either
+// fully excluded or untouched.
+func subtractPrefixes(result []netip.Prefix, prefix netip.Prefix, excluded []netip.Prefix) []netip.Prefix {
+	if len(excluded) == 0 {
+		return append(result, prefix)
+	}
+	if excluded[0].Bits() <= prefix.Bits() {
+		return result
+	}
+
+	lower := netip.PrefixFrom(prefix.Addr(), prefix.Bits()+1)
+	upper := netip.PrefixFrom(PrefixLastAddr(lower).Next(), prefix.Bits()+1)
+	split := 0
+	for split < len(excluded) && lower.Overlaps(excluded[split]) {
+		split++
+	}
+	result = subtractPrefixes(result, lower, excluded[:split])
+	return subtractPrefixes(result, upper, excluded[split:])
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+func mustPrefixSet(t *testing.T, cidrs ...string) PrefixSet {
+	t.Helper()
+
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefixes = append(prefixes, mustParsePrefix(t, cidr))
+	}
+	return NewPrefixSet(prefixes...)
+}
+
+func TestPrefixSetOperations(t *testing.T) {
+	cloud := mustPrefixSet(t, "10.0.0.0/22", "10.0.8.0/24", "2001:db8::/32")
+	ours := mustPrefixSet(t, "10.0.1.0/24", "10.0.8.128/25", "192.168.0.0/16", "2001:db8:8000::/33")
+
+	assertPrefixes(t, "Union", cloud.Union(ours).Prefixes(), []string{"10.0.0.0/22", "10.0.8.0/24", "192.168.0.0/16", "2001:db8::/32"})
+	assertPrefixes(t, "Intersect", cloud.Intersect(ours).Prefixes(), []string{"10.0.1.0/24", "10.0.8.128/25", "2001:db8:8000::/33"})
+	assertPrefixes(t, "Difference", cloud.Difference(ours).Prefixes(), []string{"10.0.0.0/24", "10.0.2.0/23", "10.0.8.0/25", "2001:db8::/33"})
+	assertPrefixes(t, "Difference(reverse)", ours.Difference(cloud).Prefixes(), []string{"192.168.0.0/16"})
+
+	if !cloud.Contains(netip.MustParseAddr("::ffff:10.0.3.1")) || cloud.Contains(netip.MustParseAddr("10.0.4.1")) {
+		t.Error("Contains() does not match the set prefixes")
+	}
+	if empty := (PrefixSet{}); empty.Len() != 0 || empty.Union(cloud).Len() != cloud.Len() || cloud.Intersect(empty).Len() != 0 {
+		t.Error("the zero PrefixSet does not behave as the empty set")
+	}
+}
+
+func TestPrefixSetMatchesNaiveReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	randomSet := func() (PrefixSet, map[byte]bool) {
+		var prefixes []netip.Prefix
+		addresses := make(map[byte]bool)
+		for i := 0; i < random.Intn(10); i++ {
+			prefix := netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 0, 0, byte(random.Intn(256))}), 24+random.Intn(9)).Masked()
+			prefixes = append(prefixes, prefix)
+			for host := 0; host < 1<<(32-prefix.Bits()); host++ {
+				addresses[prefix.Addr().As4()[3]+byte(host)] = true
+			}
+		}
+		return NewPrefixSet(prefixes...), addresses
+	}
+
+	for round := 0; round < 500; round++ {
+		a, inA := randomSet()
+		b, inB := randomSet()
+
+		tests := []struct {
+			name string
+			got  PrefixSet
+			want func(host byte) bool
+		}{
+			{"Union", a.Union(b), func(host byte) bool { return inA[host] || inB[host] }},
+			{"Intersect", a.Intersect(b), func(host byte) bool { return inA[host] && inB[host] }},
+			{"Difference", a.Difference(b), func(host byte) bool { return inA[host] && !inB[host] }},
+		}
+
+		for _, tt := range tests {
+			assertPrefixes(t, tt.name+" is aggregated", AggregatePrefixes(tt.got.Prefixes()), prefixStrings(tt.got.Prefixes()))
+			for host := 0; host < 256; host++ {
+				addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(host)})
+				if got := tt.got.Contains(addr); got != tt.want(byte(host)) {
+					t.Fatalf("%s(%v, %v) = %v, Contains(%s) = %v, want %v", tt.name, a.Prefixes(), b.Prefixes(), tt.got.Prefixes(), addr, got, !got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package util
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+func prefixStrings(prefixes []netip.Prefix) []string {
+	values := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		values = append(values, prefix.String())
+	}
+	return values
+}
+
+func assertPrefixes(t *testing.T, name string, got []netip.Prefix, want []string) {
+	t.Helper()
+
+	values := prefixStrings(got)
+	if len(values) != len(want) {
+		t.Fatalf("%s = %v, want %v", name, values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Fatalf("%s = %v, want %v", name, values, want)
+		}
+	}
+}
+
+func TestParseAddressRange(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"10.0.0.1-10.0.0.200", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/29", "10.0.0.200/32"}},
+		{"10.0.0.0-10.0.1.255", []string{"10.0.0.0/23"}},
+		{"10.0.0.5-10.0.0.5", []string{"10.0.0.5/32"}},
+		{"0.0.0.0-255.255.255.255", []string{"0.0.0.0/0"}},
+		{"2001:db8::-2001:db8::ffff", []string{"2001:db8::/112"}},
+		{"::ffff:10.0.0.0-10.0.0.3", []string{"10.0.0.0/30"}},
+	}
+
+	for _, tt := range tests {
+		prefixes, err := ParseAddressRange(tt.value)
+		if err != nil {
+			t.Fatalf("ParseAddressRange(%q) returned error: %v", tt.value, err)
+		}
+		assertPrefixes(t, "ParseAddressRange("+tt.value+")", prefixes, tt.want)
+	}
+}
+
+func TestParseAddressRangeRejectsInvalidRanges(t *testing.T) {
+	for _, value := range []string{"10.0.0.1", "10.0.0.9-10.0.0.1", "10.0.0.1-2001:db8::1", "10.0.0.1-bad"} {
+		if _, err := ParseAddressRange(value); err == nil {
+			t.Errorf("ParseAddressRange(%q) returned no error", value)
+		}
+	}
+}
+
+func TestPrefixLastAddr(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.0/8":    "10.255.255.255",
+		"10.1.2.3/32":   "10.1.2.3",
+		"0.0.0.0/0":     "255.255.255.255",
+		"2001:db8::/32": "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+	}
+
+	for cidr, want := range tests {
+		if got := PrefixLastAddr(netip.MustParsePrefix(cidr)).String(); got != want {
+			t.Errorf("PrefixLastAddr(%s) = %s, want %s", cidr, got, want)
+		}
+	}
+}
+
+func TestAggregatePrefixes(t *testing.T) {
+	var prefixes []netip.Prefix
+	for _, cidr := range []string{"10.0.1.0/24", "2001:db8::/33", "10.0.0.0/24", "10.0.0.128/25", "10.0.3.0/24", "2001:db8:8000::/33", "10.0.2.0/24"} {
+		prefixes = append(prefixes, mustParsePrefix(t, cidr))
+	}
+
+	assertPrefixes(t, "AggregatePrefixes", AggregatePrefixes(prefixes), []string{"10.0.0.0/22", "2001:db8::/32"})
+	assertPrefixes(t, "AggregatePrefixes(nil)", AggregatePrefixes(nil), nil)
+}
+
+func TestAggregatePrefixesMatchesNaiveReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 200; round++ {
+		var prefixes []netip.Prefix
+		covered := make(map[byte]bool)
+		for i := 0; i < 1+random.Intn(12); i++ {
+			prefix := netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 0, 0, byte(random.Intn(256))}), 24+random.Intn(9)).Masked()
+			prefixes = append(prefixes, prefix)
+			for host := 0; host < 1<<(32-prefix.Bits()); host++ {
+				covered[prefix.Addr().As4()[3]+byte(host)] = true
+			}
+		}
+
+		aggregated := AggregatePrefixes(prefixes)
+		got := make(map[byte]bool)
+		for i, prefix := range aggregated {
+			for host := 0; host < 1<<(32-prefix.Bits()); host++ {
+				got[prefix.Addr().As4()[3]+byte(host)] = true
+			}
+			if i == 0 {
+				continue
+			}
+			previous := aggregated[i-1]
+			if !PrefixLastAddr(previous).Less(prefix.Addr()) {
+				t.Fatalf("AggregatePrefixes(%v) = %v, prefixes overlap or are unsorted", prefixes, aggregated)
+			}
+			parent := netip.PrefixFrom(previous.Addr(), previous.Bits()-1).Masked()
+			if previous.Bits() == prefix.Bits() && parent.Addr() == previous.Addr() && parent.Contains(prefix.Addr()) {
+				t.Fatalf("AggregatePrefixes(%v) = %v, siblings %s and %s were not merged", prefixes, aggregated, previous, prefix)
+			}
+		}
+
+		if len(got) != len(covered) {
+			t.Fatalf("AggregatePrefixes(%v) = %v covers %d addresses, want %d", prefixes, aggregated, len(got), len(covered))
+		}
+		for host := range covered {
+			if !got[host] {
+				t.Fatalf("AggregatePrefixes(%v) = %v misses 10.0.0.%d", prefixes, aggregated, host)
+			}
+		}
+	}
+}
+
+func TestSummarizePrefixes(t *testing.T) {
+	var prefixes []netip.Prefix
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.2.0/24", "10.0.8.0/24", "10.0.9.0/25", "192.168.0.0/24", "2001:db8::/48", "2001:db8:1::/48"} {
+		prefixes = append(prefixes, mustParsePrefix(t, cidr))
+	}
+
+	assertPrefixes(t, "SummarizePrefixes(0)", SummarizePrefixes(prefixes, 0), []string{"10.0.0.0/24", "10.0.2.0/24", "10.0.8.0/24", "10.0.9.0/25", "192.168.0.0/24", "2001:db8::/47"})
+	assertPrefixes(t, "SummarizePrefixes(5)", SummarizePrefixes(prefixes, 5), []string{"10.0.0.0/24", "10.0.2.0/24", "10.0.8.0/23", "192.168.0.0/24", "2001:db8::/47"})
+	assertPrefixes(t, "SummarizePrefixes(4)", SummarizePrefixes(prefixes, 4), []string{"10.0.0.0/22", "10.0.8.0/23", "192.168.0.0/24", "2001:db8::/47"})
+	assertPrefixes(t, "SummarizePrefixes(3)", SummarizePrefixes(prefixes, 3), []string{"10.0.0.0/20", "192.168.0.0/24", "2001:db8::/47"})
+	assertPrefixes(t, "SummarizePrefixes(1)", SummarizePrefixes(prefixes, 1), []string{"0.0.0.0/0", "2001:db8::/47"})
+}
+
+func TestSummarizePrefixesCoversInput(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 200; round++ {
+		var prefixes []netip.Prefix
+		for i := 0; i < 1+random.Intn(40); i++ {
+			addr := netip.AddrFrom4([4]byte{10, byte(random.Intn(4)), byte(random.Intn(256)), byte(random.Intn(256))})
+			prefixes = append(prefixes, netip.PrefixFrom(addr, 16+random.Intn(17)).Masked())
+		}
+		maxEntries := 1 + random.Intn(10)
+
+		summarized := SummarizePrefixes(prefixes, maxEntries)
+		if len(summarized) > maxEntries {
+			t.Fatalf("SummarizePrefixes(%v, %d) = %v, too many entries", prefixes, maxEntries, summarized)
+		}
+		assertPrefixes(t, "AggregatePrefixes(SummarizePrefixes)", AggregatePrefixes(summarized), prefixStrings(summarized))
+		for _, prefix := range prefixes {
+			covered := false
+			for _, candidate := range summarized {
+				if candidate.Contains(prefix.Addr()) && candidate.Bits() <= prefix.Bits() {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				t.Fatalf("SummarizePrefixes(%v, %d) = %v misses %s", prefixes, maxEntries, summarized, prefix)
+			}
+		}
+	}
+}
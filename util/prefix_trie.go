@@ -0,0 +1,373 @@
+package util
+
+import (
+	"fmt"
+	"math/bits"
+	"net"
+	"net/netip"
+)
+
+// PrefixTrie is a path-compressed binary radix trie keyed by netip.Prefix.
+// IPv4 and IPv6 prefixes are kept under separate roots and every stored prefix
+// carries a payload of type T.
+type PrefixTrie[T any] struct {
+	root4 *trieNode[T]
+	root6 *trieNode[T]
+	size  int
+}
+
+// PrefixEntry is a prefix stored in a PrefixTrie with its payload.
+type PrefixEntry[T any] struct {
+	Prefix netip.Prefix
+	Value  T
+}
+
+// trieNode is either a stored prefix or a branching point shared by the
+// prefixes below it. Branching points without a value only exist while they
+// have two children.
+type trieNode[T any] struct {
+	prefix   netip.Prefix
+	children [2]*trieNode[T]
+	value    T
+	hasValue bool
+}
+
+// NewPrefixTrie Create new prefix trie
+func NewPrefixTrie[T any]() *PrefixTrie[T] {
+	return &PrefixTrie[T]{}
+}
+
+// ParsePrefix parses a CIDR string into its masked prefix. IPv4-mapped IPv6
+// prefixes are converted to IPv4.
+func ParsePrefix(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	return normalizePrefix(prefix), nil
+}
+
+// AddrFromParsedIP converts a parsed net.IP into a netip.Addr without allocating.
+func AddrFromParsedIP(parsedIP net.IP) (netip.Addr, bool) {
+	if ipv4 := parsedIP.To4(); ipv4 != nil {
+		return netip.AddrFrom4([4]byte(ipv4)), true
+	}
+	if len(parsedIP) != net.IPv6len {
+		return netip.Addr{}, false
+	}
+	return netip.AddrFrom16([16]byte(parsedIP)), true
+}
+
+func normalizePrefix(prefix netip.Prefix) netip.Prefix {
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked()
+}
+
+// Len returns the number of stored prefixes.
+func (trie *PrefixTrie[T]) Len() int {
+	return trie.size
+}
+
+func (trie *PrefixTrie[T]) rootFor(addr netip.Addr) **trieNode[T] {
+	if addr.Is4() {
+		return &trie.root4
+	}
+	return &trie.root6
+}
+
+// Insert stores the value for the prefix, replacing any previous value.
+func (trie *PrefixTrie[T]) Insert(prefix netip.Prefix, value T) {
+	prefix = normalizePrefix(prefix)
+	link := trie.rootFor(prefix.Addr())
+	for {
+		node := *link
+		if node == nil {
+			*link = &trieNode[T]{prefix: prefix, value: value, hasValue: true}
+			trie.size++
+			return
+		}
+
+		common := commonPrefixBits(node.prefix, prefix)
+		switch {
+		case common == node.prefix.Bits() && common == prefix.Bits():
+			if !node.hasValue {
+				trie.size++
+			}
+			node.value = value
+			node.hasValue = true
+			return
+		case common == node.prefix.Bits():
+			link = &node.children[addrBit(prefix.Addr(), common)]
+		case common == prefix.Bits():
+			leaf := &trieNode[T]{prefix: prefix, value: value, hasValue: true}
+			leaf.children[addrBit(node.prefix.Addr(), common)] = node
+			*link = leaf
+			trie.size++
+			return
+		default:
+			branch := &trieNode[T]{prefix: netip.PrefixFrom(prefix.Addr(), common).Masked()}
+			branch.children[addrBit(node.prefix.Addr(), common)] = node
+			branch.children[addrBit(prefix.Addr(), common)] = &trieNode[T]{prefix: prefix, value: value, hasValue: true}
+			*link = branch
+			trie.size++
+			return
+		}
+	}
+}
+
+// Get returns the value stored for exactly the prefix.
+func (trie *PrefixTrie[T]) Get(prefix netip.Prefix) (T, bool) {
+	prefix = normalizePrefix(prefix)
+	node := *trie.rootFor(prefix.Addr())
+	for node != nil && node.prefix.Bits() <= prefix.Bits() && node.prefix.Contains(prefix.Addr()) {
+		if node.prefix.Bits() == prefix.Bits() {
+			return node.value, node.hasValue
+		}
+		node = node.children[addrBit(prefix.Addr(), node.prefix.Bits())]
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Delete removes the prefix and reports whether it was stored.
+func (trie *PrefixTrie[T]) Delete(prefix netip.Prefix) bool {
+	prefix = normalizePrefix(prefix)
+	link := trie.rootFor(prefix.Addr())
+	var parentLink **trieNode[T]
+	for {
+		node := *link
+		if node == nil || node.prefix.Bits() > prefix.Bits() || !node.prefix.Contains(prefix.Addr()) {
+			return false
+		}
+		if node.prefix.Bits() < prefix.Bits() {
+			parentLink = link
+			link = &node.children[addrBit(prefix.Addr(), node.prefix.Bits())]
+			continue
+		}
+		if !node.hasValue {
+			return false
+		}
+
+		var zero T
+		node.value = zero
+		node.hasValue = false
+		trie.size--
+		compactLink(link)
+		if parentLink != nil {
+			compactLink(parentLink)
+		}
+		return true
+	}
+}
+
+// compactLink removes a node without a value that no longer branches.
+func compactLink[T any](link **trieNode[T]) {
+	node := *link
+	if node == nil || node.hasValue {
+		return
+	}
+	switch {
+	case node.children[0] == nil:
+		*link = node.children[1]
+	case node.children[1] == nil:
+		*link = node.children[0]
+	}
+}
+
+// Contains reports whether any stored prefix contains the address.
+func (trie *PrefixTrie[T]) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	node := *trie.rootFor(addr)
+	for node != nil && node.prefix.Contains(addr) {
+		if node.hasValue {
+			return true
+		}
+		if node.prefix.Bits() == addr.BitLen() {
+			return false
+		}
+		node = node.children[addrBit(addr, node.prefix.Bits())]
+	}
+	return false
+}
+
+// ContainsParsedIP reports whether any stored prefix contains the parsed IP.
+func (trie *PrefixTrie[T]) ContainsParsedIP(parsedIP net.IP) bool {
+	addr, ok := AddrFromParsedIP(parsedIP)
+	if !ok {
+		return false
+	}
+	return trie.Contains(addr)
+}
+
+// LongestMatch returns the most specific stored prefix containing the address.
+func (trie *PrefixTrie[T]) LongestMatch(addr netip.Addr) (PrefixEntry[T], bool) {
+	addr = addr.Unmap()
+	var match *trieNode[T]
+	node := *trie.rootFor(addr)
+	for node != nil && node.prefix.Contains(addr) {
+		if node.hasValue {
+			match = node
+		}
+		if node.prefix.Bits() == addr.BitLen() {
+			break
+		}
+		node = node.children[addrBit(addr, node.prefix.Bits())]
+	}
+
+	if match == nil {
+		return PrefixEntry[T]{}, false
+	}
+	return PrefixEntry[T]{Prefix: match.prefix, Value: match.value}, true
+}
+
+// LongestMatchParsedIP returns the most specific stored prefix containing the parsed IP.
+func (trie *PrefixTrie[T]) LongestMatchParsedIP(parsedIP net.IP) (PrefixEntry[T], bool) {
+	addr, ok := AddrFromParsedIP(parsedIP)
+	if !ok {
+		return PrefixEntry[T]{}, false
+	}
+	return trie.LongestMatch(addr)
+}
+
+// AllMatches returns every stored prefix containing the address, from the
+// least to the most specific.
+func (trie *PrefixTrie[T]) AllMatches(addr netip.Addr) []PrefixEntry[T] {
+	addr = addr.Unmap()
+	var matches []PrefixEntry[T]
+	node := *trie.rootFor(addr)
+	for node != nil && node.prefix.Contains(addr) {
+		if node.hasValue {
+			matches = append(matches, PrefixEntry[T]{Prefix: node.prefix, Value: node.value})
+		}
+		if node.prefix.Bits() == addr.BitLen() {
+			break
+		}
+		node = node.children[addrBit(addr, node.prefix.Bits())]
+	}
+	return matches
+}
+
+// AllMatchesParsedIP returns every stored prefix containing the parsed IP.
+func (trie *PrefixTrie[T]) AllMatchesParsedIP(parsedIP net.IP) []PrefixEntry[T] {
+	addr, ok := AddrFromParsedIP(parsedIP)
+	if !ok {
+		return nil
+	}
+	return trie.AllMatches(addr)
+}
+
+// Supernets returns every stored prefix containing the whole prefix, including
+// the prefix itself, from the least to the most specific.
+func (trie *PrefixTrie[T]) Supernets(prefix netip.Prefix) []PrefixEntry[T] {
+	prefix = normalizePrefix(prefix)
+	var entries []PrefixEntry[T]
+	node := *trie.rootFor(prefix.Addr())
+	for node != nil && node.prefix.Bits() <= prefix.Bits() && node.prefix.Contains(prefix.Addr()) {
+		if node.hasValue {
+			entries = append(entries, PrefixEntry[T]{Prefix: node.prefix, Value: node.value})
+		}
+		if node.prefix.Bits() == prefix.Bits() {
+			break
+		}
+		node = node.children[addrBit(prefix.Addr(), node.prefix.Bits())]
+	}
+	return entries
+}
+
+// Subnets returns every stored prefix strictly inside the prefix in the order of Walk.
+func (trie *PrefixTrie[T]) Subnets(prefix netip.Prefix) []PrefixEntry[T] {
+	prefix = normalizePrefix(prefix)
+	node := *trie.rootFor(prefix.Addr())
+	for node != nil && node.prefix.Bits() <= prefix.Bits() {
+		if !node.prefix.Contains(prefix.Addr()) {
+			return nil
+		}
+		if node.prefix.Bits() == prefix.Bits() {
+			break
+		}
+		node = node.children[addrBit(prefix.Addr(), node.prefix.Bits())]
+	}
+	if node == nil || !prefix.Contains(node.prefix.Addr()) {
+		return nil
+	}
+
+	var entries []PrefixEntry[T]
+	collect := func(subnet netip.Prefix, value T) bool {
+		if subnet.Bits() > prefix.Bits() {
+			entries = append(entries, PrefixEntry[T]{Prefix: subnet, Value: value})
+		}
+		return true
+	}
+	walkNode(node, collect)
+	return entries
+}
+
+// Walk visits every stored prefix in address order, IPv4 before IPv6 and
+// shorter prefixes before the longer prefixes they contain. Returning false
+// from fn stops the walk.
+func (trie *PrefixTrie[T]) Walk(fn func(prefix netip.Prefix, value T) bool) {
+	if walkNode(trie.root4, fn) {
+		walkNode(trie.root6, fn)
+	}
+}
+
+func walkNode[T any](node *trieNode[T], fn func(netip.Prefix, T) bool) bool {
+	if node == nil {
+		return true
+	}
+	if node.hasValue && !fn(node.prefix, node.value) {
+		return false
+	}
+	return walkNode(node.children[0], fn) && walkNode(node.children[1], fn)
+}
+
+// Entries returns every stored prefix in the order of Walk.
+func (trie *PrefixTrie[T]) Entries() []PrefixEntry[T] {
+	entries := make([]PrefixEntry[T], 0, trie.size)
+	trie.Walk(func(prefix netip.Prefix, value T) bool {
+		entries = append(entries, PrefixEntry[T]{Prefix: prefix, Value: value})
+		return true
+	})
+	return entries
+}
+
+// addrBit returns the bit of the address at index, counting from the most significant bit.
+func addrBit(addr netip.Addr, index int) int {
+	if addr.Is4() {
+		octets := addr.As4()
+		return int(octets[index/8]>>(7-index%8)) & 1
+	}
+	octets := addr.As16()
+	return int(octets[index/8]>>(7-index%8)) & 1
+}
+
+// commonPrefixBits returns the number of leading bits shared by both prefixes,
+// capped by the shorter prefix length.
+func commonPrefixBits(a, b netip.Prefix) int {
+	limit := min(a.Bits(), b.Bits())
+	aBytes, bBytes := addrBytes(a.Addr()), addrBytes(b.Addr())
+
+	common := 0
+	for i := 0; i < len(aBytes) && common < limit; i++ {
+		diff := aBytes[i] ^ bBytes[i]
+		if diff != 0 {
+			common += bits.LeadingZeros8(diff)
+			break
+		}
+		common += 8
+	}
+	return min(common, limit)
+}
+
+func addrBytes(addr netip.Addr) [16]byte {
+	if addr.Is4() {
+		var octets [16]byte
+		v4 := addr.As4()
+		copy(octets[:], v4[:])
+		return octets
+	}
+	return addr.As16()
+}
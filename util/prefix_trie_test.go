@@ -0,0 +1,263 @@
+package util
+
+import (
+	"math/rand"
+	"net/netip"
+	"sort"
+	"testing"
+)
+
+func mustParsePrefix(tb testing.TB, raw string) netip.Prefix {
+	tb.Helper()
+
+	prefix, err := ParsePrefix(raw)
+	if err != nil {
+		tb.Fatalf("failed to parse prefix %q: %v", raw, err)
+	}
+	return prefix
+}
+
+func TestPrefixTrieInsertAndGet(t *testing.T) {
+	trie := NewPrefixTrie[string]()
+	trie.Insert(mustParsePrefix(t, "10.0.0.0/8"), "wide")
+	trie.Insert(mustParsePrefix(t, "10.1.0.0/16"), "narrow")
+	trie.Insert(mustParsePrefix(t, "10.1.0.0/16"), "replaced")
+	trie.Insert(mustParsePrefix(t, "2001:db8::/32"), "v6")
+
+	if trie.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", trie.Len())
+	}
+
+	tests := []struct {
+		prefix string
+		want   string
+		found  bool
+	}{
+		{"10.0.0.0/8", "wide", true},
+		{"10.1.0.0/16", "replaced", true},
+		{"10.1.2.3/16", "replaced", true},
+		{"2001:db8::/32", "v6", true},
+		{"10.0.0.0/9", "", false},
+		{"10.1.0.0/24", "", false},
+		{"::ffff:10.0.0.0/104", "wide", true},
+	}
+
+	for _, tt := range tests {
+		got, found := trie.Get(mustParsePrefix(t, tt.prefix))
+		if found != tt.found || got != tt.want {
+			t.Errorf("Get(%s) = %q, %v, want %q, %v", tt.prefix, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestPrefixTrieMatches(t *testing.T) {
+	trie := NewPrefixTrie[string]()
+	for _, cidr := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.2.0.0/16", "2001:db8::/32"} {
+		trie.Insert(mustParsePrefix(t, cidr), cidr)
+	}
+
+	addr := netip.MustParseAddr("10.1.2.3")
+	entry, ok := trie.LongestMatch(addr)
+	if !ok || entry.Value != "10.1.2.0/24" {
+		t.Fatalf("LongestMatch(%s) = %v, %v, want 10.1.2.0/24", addr, entry, ok)
+	}
+
+	var got []string
+	for _, entry := range trie.AllMatches(addr) {
+		got = append(got, entry.Value)
+	}
+	want := []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"}
+	if len(got) != len(want) {
+		t.Fatalf("AllMatches(%s) = %v, want %v", addr, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("AllMatches(%s) = %v, want %v", addr, got, want)
+		}
+	}
+
+	if !trie.Contains(netip.MustParseAddr("::ffff:10.2.0.1")) {
+		t.Error("Contains(::ffff:10.2.0.1) = false, want IPv4-mapped address to match")
+	}
+	if trie.Contains(netip.MustParseAddr("192.168.0.1")) {
+		t.Error("Contains(192.168.0.1) = true, want false")
+	}
+	if _, ok := trie.LongestMatch(netip.MustParseAddr("2001:db9::1")); ok {
+		t.Error("LongestMatch(2001:db9::1) matched, want no match")
+	}
+}
+
+func TestPrefixTrieDelete(t *testing.T) {
+	trie := NewPrefixTrie[int]()
+	for i, cidr := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16", "10.1.2.0/24"} {
+		trie.Insert(mustParsePrefix(t, cidr), i)
+	}
+
+	if !trie.Delete(mustParsePrefix(t, "10.1.0.0/16")) {
+		t.Fatal("Delete(10.1.0.0/16) = false, want true")
+	}
+	if trie.Delete(mustParsePrefix(t, "10.1.0.0/16")) {
+		t.Fatal("second Delete(10.1.0.0/16) = true, want false")
+	}
+	if trie.Delete(mustParsePrefix(t, "10.3.0.0/16")) {
+		t.Fatal("Delete(10.3.0.0/16) = true, want false")
+	}
+	if trie.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", trie.Len())
+	}
+
+	entry, ok := trie.LongestMatch(netip.MustParseAddr("10.1.3.1"))
+	if !ok || entry.Prefix.String() != "10.0.0.0/8" {
+		t.Fatalf("LongestMatch(10.1.3.1) = %v, %v, want 10.0.0.0/8", entry, ok)
+	}
+	entry, ok = trie.LongestMatch(netip.MustParseAddr("10.1.2.1"))
+	if !ok || entry.Prefix.String() != "10.1.2.0/24" {
+		t.Fatalf("LongestMatch(10.1.2.1) = %v, %v, want 10.1.2.0/24", entry, ok)
+	}
+}
+
+func TestPrefixTrieWalkIsOrdered(t *testing.T) {
+	trie := NewPrefixTrie[string]()
+	for _, cidr := range []string{"2001:db8::/32", "192.168.0.0/16", "10.1.0.0/16", "10.0.0.0/8", "10.0.0.0/16", "::/0"} {
+		trie.Insert(mustParsePrefix(t, cidr), cidr)
+	}
+
+	var got []string
+	trie.Walk(func(prefix netip.Prefix, _ string) bool {
+		got = append(got, prefix.String())
+		return true
+	})
+
+	want := []string{"10.0.0.0/8", "10.0.0.0/16", "10.1.0.0/16", "192.168.0.0/16", "::/0", "2001:db8::/32"}
+	if len(got) != len(want) {
+		t.Fatalf("Walk() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Walk() = %v, want %v", got, want)
+		}
+	}
+
+	visited := 0
+	trie.Walk(func(netip.Prefix, string) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Fatalf("Walk() visited %d prefixes after stop, want 2", visited)
+	}
+}
+
+func TestPrefixTrieMatchesNaiveReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	trie := NewPrefixTrie[int]()
+	reference := make(map[netip.Prefix]int)
+
+	randomPrefix := func() netip.Prefix {
+		addr := netip.AddrFrom4([4]byte{10, byte(random.Intn(4)), byte(random.Intn(256)), byte(random.Intn(256))})
+		return netip.PrefixFrom(addr, 8+random.Intn(25)).Masked()
+	}
+
+	for i := 0; i < 5000; i++ {
+		prefix := randomPrefix()
+		if random.Intn(3) == 0 {
+			_, stored := reference[prefix]
+			if got := trie.Delete(prefix); got != stored {
+				t.Fatalf("Delete(%s) = %v, want %v", prefix, got, stored)
+			}
+			delete(reference, prefix)
+			continue
+		}
+		trie.Insert(prefix, i)
+		reference[prefix] = i
+	}
+
+	if trie.Len() != len(reference) {
+		t.Fatalf("Len() = %d, want %d", trie.Len(), len(reference))
+	}
+
+	for i := 0; i < 5000; i++ {
+		addr := netip.AddrFrom4([4]byte{10, byte(random.Intn(4)), byte(random.Intn(256)), byte(random.Intn(256))})
+
+		var want []netip.Prefix
+		for prefix := range reference {
+			if prefix.Contains(addr) {
+				want = append(want, prefix)
+			}
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].Bits() < want[j].Bits() })
+
+		got := trie.AllMatches(addr)
+		if len(got) != len(want) {
+			t.Fatalf("AllMatches(%s) returned %d prefixes, want %d", addr, len(got), len(want))
+		}
+		for j := range want {
+			if got[j].Prefix != want[j] || got[j].Value != reference[want[j]] {
+				t.Fatalf("AllMatches(%s)[%d] = %v, want %s=%d", addr, j, got[j], want[j], reference[want[j]])
+			}
+		}
+
+		longest, ok := trie.LongestMatch(addr)
+		if ok != (len(want) > 0) || (ok && longest.Prefix != want[len(want)-1]) {
+			t.Fatalf("LongestMatch(%s) = %v, %v, want %v", addr, longest, ok, want)
+		}
+		if trie.Contains(addr) != ok {
+			t.Fatalf("Contains(%s) disagrees with LongestMatch", addr)
+		}
+	}
+
+	entries := trie.Entries()
+	if len(entries) != len(reference) {
+		t.Fatalf("Entries() returned %d prefixes, want %d", len(entries), len(reference))
+	}
+	for i := 1; i < len(entries); i++ {
+		previous, current := entries[i-1].Prefix, entries[i].Prefix
+		if cmp := previous.Addr().Compare(current.Addr()); cmp > 0 || (cmp == 0 && previous.Bits() >= current.Bits()) {
+			t.Fatalf("Entries() out of order: %s before %s", previous, current)
+		}
+	}
+}
+
+func TestPrefixTrieContainsParsedIPHasNoAllocs(t *testing.T) {
+	trie := NewPrefixTrie[string]()
+	trie.Insert(mustParsePrefix(t, "10.50.0.0/24"), "10.50.0.0/24")
+	parsedIP := mustParseIP(t, "10.50.0.1")
+
+	var matched bool
+	allocs := testing.AllocsPerRun(1000, func() {
+		matched = trie.ContainsParsedIP(parsedIP)
+		_, _ = trie.LongestMatchParsedIP(parsedIP)
+	})
+
+	if !matched {
+		t.Fatal("expected ContainsParsedIP to return true")
+	}
+	if allocs != 0 {
+		t.Fatalf("ContainsParsedIP and LongestMatchParsedIP should not allocate, got %.2f allocs/run", allocs)
+	}
+}
+
+func TestPrefixTrieSupernetsAndSubnets(t *testing.T) {
+	trie := NewPrefixTrie[string]()
+	for _, cidr := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.1.3.0/24", "10.2.0.0/16", "2001:db8::/32"} {
+		trie.Insert(mustParsePrefix(t, cidr), cidr)
+	}
+
+	entryPrefixes := func(entries []PrefixEntry[string]) []netip.Prefix {
+		prefixes := make([]netip.Prefix, 0, len(entries))
+		for _, entry := range entries {
+			prefixes = append(prefixes, entry.Prefix)
+		}
+		return prefixes
+	}
+
+	assertPrefixes(t, "Supernets(10.1.2.0/25)", entryPrefixes(trie.Supernets(mustParsePrefix(t, "10.1.2.0/25"))), []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"})
+	assertPrefixes(t, "Supernets(10.1.0.0/16)", entryPrefixes(trie.Supernets(mustParsePrefix(t, "10.1.0.0/16"))), []string{"10.0.0.0/8", "10.1.0.0/16"})
+	assertPrefixes(t, "Supernets(10.0.0.0/7)", entryPrefixes(trie.Supernets(mustParsePrefix(t, "10.0.0.0/7"))), nil)
+
+	assertPrefixes(t, "Subnets(10.0.0.0/8)", entryPrefixes(trie.Subnets(mustParsePrefix(t, "10.0.0.0/8"))), []string{"10.1.0.0/16", "10.1.2.0/24", "10.1.3.0/24", "10.2.0.0/16"})
+	assertPrefixes(t, "Subnets(10.1.0.0/23)", entryPrefixes(trie.Subnets(mustParsePrefix(t, "10.1.2.0/23"))), []string{"10.1.2.0/24", "10.1.3.0/24"})
+	assertPrefixes(t, "Subnets(10.0.0.0/7)", entryPrefixes(trie.Subnets(mustParsePrefix(t, "10.0.0.0/7"))), []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "10.1.3.0/24", "10.2.0.0/16"})
+	assertPrefixes(t, "Subnets(10.3.0.0/16)", entryPrefixes(trie.Subnets(mustParsePrefix(t, "10.3.0.0/16"))), nil)
+	assertPrefixes(t, "Subnets(::/0)", entryPrefixes(trie.Subnets(mustParsePrefix(t, "::/0"))), []string{"2001:db8::/32"})
+}
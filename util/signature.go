@@ -0,0 +1,13 @@
+package util
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// ContentSignature identifies a data version by its content, for sources
+// served without an ETag, Last-Modified header or version field.
+func ContentSignature(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%x", sum[:8])
+}